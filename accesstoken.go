@@ -1,18 +1,60 @@
 package asana
 
 import (
-	"context"
+	"net/http"
 
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
+// AuthTransport is an http.RoundTripper which adds an Authorization header
+// with a bearer token from Source to each request. The token may be a
+// Personal Access Token or service account token wrapped in
+// oauth2.StaticTokenSource, or an OAuth token source which refreshes itself.
+type AuthTransport struct {
+	Source oauth2.TokenSource
+
+	// The transport used to send requests. Defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *AuthTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token()
+	if err != nil {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return nil, errors.Wrap(err, "Unable to get access token")
+	}
+
+	// A RoundTripper must not modify the request it is given
+	authorized := request.Clone(request.Context())
+	token.SetAuthHeader(authorized)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(authorized)
+}
+
 // NewClientWithAccessToken creates a new instance of the Asana client which uses a
-// Personal Access Token for authentication
-func NewClientWithAccessToken(accessToken string) *Client {
-	ctx := context.Background()
-	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{
+// Personal Access Token or service account token for authentication
+func NewClientWithAccessToken(accessToken string, opts ...ClientOption) (*Client, error) {
+	if accessToken == "" {
+		return nil, errors.New("An access token is required")
+	}
+	return NewClientWithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: accessToken,
-	})
-	client := oauth2.NewClient(ctx, tokenSource)
-	return NewClient(client)
+	}), opts...)
+}
+
+// NewClientWithTokenSource creates a new instance of the Asana client which
+// authenticates with tokens from source, such as an OAuth token source
+func NewClientWithTokenSource(source oauth2.TokenSource, opts ...ClientOption) (*Client, error) {
+	// Limit the capacity so that appending copies the caller's options
+	// rather than writing into their spare capacity
+	return NewClient(append(opts[:len(opts):len(opts)], withTokenSource(source))...)
 }
@@ -0,0 +1,48 @@
+package asana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAuthTransport(t *testing.T) {
+	var userAgent, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data": {"gid": "1"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithAccessToken("pat", WithBaseURL(server.URL), WithUserAgent("test-agent"), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&User{ID: "me"}).Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer pat" || userAgent != "test-agent" {
+		t.Errorf("Unexpected headers %q %q", authorization, userAgent)
+	}
+
+	if _, err := NewClientWithAccessToken("pat", WithBaseURL("not a url")); err == nil {
+		t.Error("Expected an invalid base URL to be rejected")
+	}
+}
+
+func TestNewClientWithTokenSource_Options(t *testing.T) {
+	opts := make([]ClientOption, 1, 2)
+	opts[0] = WithUserAgent("test-agent")
+	spare := opts[:2]
+
+	if _, err := NewClientWithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "a"}), opts...); err != nil {
+		t.Fatal(err)
+	}
+	if spare[1] != nil {
+		t.Error("Expected the caller's options not to be modified")
+	}
+}
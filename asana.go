@@ -7,12 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"net/url"
-	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +20,7 @@ import (
 	"github.com/google/go-querystring/query"
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
+	"golang.org/x/oauth2"
 )
 
 const (
@@ -40,24 +41,90 @@ const (
 	ProjectPrivacySetting Feature = "project_privacy_setting"
 )
 
-// Client is the root client for the Asana API. The nested HTTPClient should provide
-// Authorization header injection.
+// Client is the root client for the Asana API. Create one with NewClient or
+// NewClientWithAccessToken. A Client is safe for concurrent use once it has
+// been created, provided its fields are not changed.
 type Client struct {
-	BaseURL    *url.URL
-	HTTPClient *http.Client
+	BaseURL *url.URL
 
+	// HTTPClient sends API requests. It should provide Authorization header
+	// injection.
+	HTTPClient Doer
+
+	// DownloadClient fetches attachment contents from their pre-signed
+	// download URLs. It must not inject an Authorization header, so it is
+	// kept separate from HTTPClient. Defaults to http.DefaultClient when nil.
+	DownloadClient *http.Client
+
+	// The number of entries in Verbose sets the log level, see
+	// WithVerbosity
 	Verbose        []bool
 	DefaultOptions Options
+
+	userAgent       string
+	timeout         time.Duration
+	uploadTimeout   time.Duration
+	transport       http.RoundTripper
+	tokenSource     oauth2.TokenSource
+	retry           RetryPolicy
+	rateLimiter     RateLimiter
+	logger          *slog.Logger
+	instrumentation Instrumentation
+	tracer          Tracer
+	cache           Cache
+	cacheTTL        map[string]time.Duration
+	requestHooks    []func(*http.Request)
+	responseHooks   []func(*http.Response)
+	middleware      []Middleware
+}
+
+// defaultTimeout limits the time taken by each request unless the client
+// sets a different one
+const defaultTimeout = 10 * time.Second
+
+func (c *Client) requestTimeout() time.Duration {
+	if c.timeout > 0 {
+		return c.timeout
+	}
+	return defaultTimeout
 }
 
-// NewClient instantiates a new Asana client with the given HTTP client and
-// the default base URL
-func NewClient(httpClient *http.Client) *Client {
+// NewClient instantiates a new Asana client with the default base URL,
+// configured by the given options. Without WithHTTPClient or a token the
+// requests are not authenticated.
+func NewClient(opts ...ClientOption) (*Client, error) {
 	u, _ := url.Parse(BaseURL)
-	return &Client{
-		BaseURL:    u,
-		HTTPClient: httpClient,
+	c := &Client{
+		BaseURL: u,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.HTTPClient == nil {
+		transport := c.transport
+		if c.tokenSource != nil {
+			transport = &AuthTransport{Source: c.tokenSource, Base: transport}
+		}
+		c.HTTPClient = &http.Client{Transport: transport}
 	}
+	return c, nil
+}
+
+func (c *Client) validate() error {
+	if c.BaseURL == nil || c.BaseURL.Scheme == "" || c.BaseURL.Host == "" {
+		return errors.Errorf("Invalid base URL %q: an absolute URL is required", c.BaseURL)
+	}
+	if c.HTTPClient != nil && (c.transport != nil || c.tokenSource != nil) {
+		return errors.New("WithHTTPClient cannot be combined with a transport or token; configure the HTTP client instead")
+	}
+	return nil
 }
 
 // request is an API request
@@ -104,6 +171,11 @@ func mergeQuery(q url.Values, request any) error {
 }
 
 func (c *Client) get(path string, data, result any, opts ...*Options) (*NextPage, error) {
+	return c.getContext(context.Background(), path, data, result, opts...)
+}
+
+// getContext is get, stopping early if ctx is done
+func (c *Client) getContext(ctx context.Context, path string, data, result any, opts ...*Options) (*NextPage, error) {
 	requestID := xid.New()
 
 	// Prepare options
@@ -112,86 +184,127 @@ func (c *Client) get(path string, data, result any, opts ...*Options) (*NextPage
 		return nil, errors.Wrapf(err, "%s unable to merge options", requestID)
 	}
 
-	// Encode default options
-	if IsTrue(options.Debug) {
-		log.Printf("%s Default options: %+v", requestID, c.DefaultOptions)
-	}
-	q, err := query.Values(c.DefaultOptions)
-	if err != nil {
-		return nil, errors.Wrapf(err, "%s Unable to marshal DefaultOptions to query parameters", requestID)
+	// Validate data
+	if validator, ok := data.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	// Encode data
-	if data != nil {
-		if IsTrue(options.Debug) {
-			log.Printf("%s Data: %+v", requestID, data)
-		}
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout())
+	defer cancel()
 
-		// Validate
-		if validator, ok := data.(Validator); ok {
-			if err := validator.Validate(); err != nil {
+	call := &Call{
+		RequestID: requestID.String(),
+		Method:    http.MethodGet,
+		Path:      path,
+		Options:   options,
+		Data:      data,
+	}
+	resultData, err := c.execute(ctx, call, func(ctx context.Context, call *Call) (*Response, error) {
+		// Encode options and data
+		q, err := query.Values(call.Options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s Unable to marshal options to query parameters", requestID)
+		}
+		if call.Data != nil {
+			if err := mergeQuery(q, call.Data); err != nil {
 				return nil, err
 			}
 		}
-
-		if err := mergeQuery(q, data); err != nil {
-			return nil, err
+		path := call.Path
+		if len(q) > 0 {
+			path = path + "?" + q.Encode()
 		}
-	}
 
-	// Encode query options
-	for _, options := range opts {
-		if IsTrue(options.Debug) {
-			log.Printf("%s Options: %+v", requestID, options)
-		}
-		if err := mergeQuery(q, options); err != nil {
-			return nil, err
+		// Serve slowly changing resources from the cache
+		ttl := c.cacheTTLFor(call.Path)
+		key := cacheKey(call.Path, q.Encode(), call.Options)
+		if ttl > 0 {
+			if value, ok := c.cachedResponse(key); ok {
+				c.trace("%s Cache hit for GET %s", requestID, path)
+				return value, nil
+			}
 		}
-	}
-	if len(q) > 0 {
-		path = path + "?" + q.Encode()
-	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+		// Make request
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getURL(path), nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s Request error", requestID)
+		}
+		c.addHeaders(request, call.Options)
+		c.dump(ctx, call.Options, requestID, "GET "+path)
 
-	// Make request
-	if IsTrue(options.Debug) {
-		log.Printf("%s GET %s", requestID, path)
-	}
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.getURL(path), nil)
+		value, err := c.roundTrip(request, requestID, call.Options)
+		if err == nil && ttl > 0 {
+			c.cacheResponse(key, value, ttl)
+		}
+		return value, err
+	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "%s Request error", requestID)
+		return nil, err
 	}
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return nil, errors.Wrapf(err, "%s GET error", requestID)
+	if items, ok := countItems(resultData.Data); ok {
+		c.instrument().Page(ctx, endpointTemplate(path), items, resultData.NextPage != nil)
 	}
 
-	// Parse the result
-	resultData, err := c.parseResponse(resp, result, requestID, options)
-	if err != nil {
+	// Decode the data field
+	if err := c.parseResponseData(resultData.Data, result, requestID); err != nil {
 		return nil, err
 	}
-
 	return resultData.NextPage, nil
 }
 
 func (c *Client) addHeaders(request *http.Request, options *Options) {
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
 	if len(options.Enable) > 0 {
 		request.Header.Add("Asana-Enable", joinFeatures(options.Enable))
 	}
 	if len(options.Disable) > 0 {
 		request.Header.Add("Asana-Disable", joinFeatures(options.Disable))
 	}
+}
 
-	if IsTrue(options.Debug) {
-		err := request.Header.Write(os.Stderr)
-		if err != nil {
-			return
+// roundTrip sends a request and decodes the response
+func (c *Client) roundTrip(request *http.Request, requestID xid.ID, options *Options) (*Response, error) {
+	c.dump(request.Context(), options, requestID, "Request headers", headersAttr("headers", request.Header))
+
+	ctx := request.Context()
+	info := &RequestInfo{
+		RequestID: requestID.String(),
+		Method:    request.Method,
+		Endpoint:  endpointTemplate(strings.TrimPrefix(request.URL.Path, c.BaseURL.Path)),
+	}
+	if request.ContentLength > 0 {
+		info.RequestBytes = request.ContentLength
+	}
+	c.instrument().RequestStart(ctx, info)
+
+	start := time.Now()
+	finish := func(resp *http.Response, value *Response, err error) {
+		info.Latency = time.Since(start)
+		info.Err = err
+		if resp != nil {
+			info.Status = resp.StatusCode
 		}
+		c.logRequest(request, requestID, resp, value, info.Latency, err)
+		c.instrument().RequestEnd(ctx, info)
+		annotateSpan(ctx, info)
+	}
+
+	resp, err := c.send(request, info)
+	if err != nil {
+		err = errors.Wrapf(err, "%s %s error", requestID, request.Method)
+		finish(nil, nil, err)
+		return nil, err
 	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &info.ResponseBytes}
+	value, err := c.parseResponse(resp, requestID, options)
+	finish(resp, value, err)
+	return value, err
 }
 
 func joinFeatures(features []Feature) string {
@@ -233,49 +346,62 @@ func (c *Client) do(method, path string, data, result interface{}, opts ...*Opti
 		}
 	}
 
-	// Build request
-	req := &request{
-		Data:    data,
-		Options: options,
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.requestTimeout())
+	defer cancel()
 
-	// Encode request body
-	body, err := json.Marshal(req)
-	if err != nil {
-		return err
-	}
+	call := &Call{
+		RequestID: requestID.String(),
+		Method:    method,
+		Path:      path,
+		Options:   options,
+		Data:      data,
+	}
+	value, err := c.execute(ctx, call, func(ctx context.Context, call *Call) (*Response, error) {
+		// Encode request body
+		body, err := json.Marshal(&request{
+			Data:    call.Data,
+			Options: call.Options,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+		// Make request
+		request, err := http.NewRequestWithContext(ctx, call.Method, c.getURL(call.Path), bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.Wrap(err, "Request error")
+		}
 
-	// Make request
-	if IsTrue(options.Debug) {
-		body, _ := json.MarshalIndent(req, "", "  ")
-		log.Printf("%s %s %s\n%s", requestID, method, path, body)
-	}
-	request, err := http.NewRequestWithContext(ctx, method, c.getURL(path), bytes.NewReader(body))
-	if err != nil {
-		return errors.Wrap(err, "Request error")
-	}
+		request.Header.Add("Content-Type", "application/json")
+		c.addHeaders(request, call.Options)
+		c.dump(ctx, call.Options, requestID, call.Method+" "+call.Path, bodyAttr("body", body))
 
-	request.Header.Add("Content-Type", "application/json")
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
+		value, err := c.roundTrip(request, requestID, call.Options)
+		if err == nil {
+			c.invalidate(call.Path)
+		}
+		return value, err
+	})
 	if err != nil {
-		return errors.Wrapf(err, "%s error", method)
+		return err
 	}
 
-	_, err = c.parseResponse(resp, result, requestID, options)
-	return err
+	// Decode the data field
+	return c.parseResponseData(value.Data, result, requestID)
 }
 
+// mergeOptions combines the options of a call with the client's defaults.
+// Later options take precedence over earlier ones. The result is a copy, so
+// that options shared between concurrent calls are not modified.
 func (c *Client) mergeOptions(opts ...*Options) (*Options, error) {
-	var options *Options
-	if opts != nil {
-		options = opts[0]
-	}
-	if options == nil {
-		options = &Options{}
+	options := &Options{}
+	for i := len(opts) - 1; i >= 0; i-- {
+		if opts[i] == nil {
+			continue
+		}
+		if err := mergo.Merge(options, *opts[i]); err != nil {
+			return nil, err
+		}
 	}
 	err := mergo.Merge(options, c.DefaultOptions)
 	return options, err
@@ -290,7 +416,28 @@ func escapeQuotes(s string) string {
 
 // --------
 
-func (c *Client) postMultipart(path string, result interface{}, field string, r io.ReadCloser, filename string, contentType string, opts ...*Options) error {
+// multipartUpload describes a multipart/form-data request body made of
+// plain form fields and an optional streamed file part
+type multipartUpload struct {
+	// Form fields written before the file part
+	Fields map[string]string
+
+	// The file part. When Reader is nil only the form fields are sent.
+	FileField   string
+	Reader      io.ReadCloser
+	FileName    string
+	ContentType string
+
+	// The length of the file part in bytes, or -1 if it is not known, in
+	// which case the body is sent without a Content-Length.
+	Size int64
+
+	// Optional. Rewind returns the file part from its start again so that
+	// the body can be resent if the request is retried.
+	Rewind func() (io.ReadCloser, error)
+}
+
+func (c *Client) postMultipart(ctx context.Context, path string, upload *multipartUpload, result interface{}, opts ...*Options) error {
 	// Make request
 	requestID := xid.New()
 	options, err := c.mergeOptions(opts...)
@@ -298,55 +445,137 @@ func (c *Client) postMultipart(path string, result interface{}, field string, r
 		return errors.Wrapf(err, "%s unable to merge options", requestID)
 	}
 
-	if IsTrue(options.Debug) {
-		log.Printf("%s POST multipart %s\n%s=%s;ContentType=%s", requestID, path, field, filename, contentType)
+	if upload.Reader != nil {
+		defer upload.Reader.Close()
+	}
+
+	// Uploads may take much longer than other requests, so they are only
+	// limited by ctx and the upload timeout
+	ctx, cancel := context.WithCancel(ctx)
+	if c.uploadTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.uploadTimeout)
+	}
+	defer cancel()
+
+	call := &Call{
+		RequestID: requestID.String(),
+		Method:    http.MethodPost,
+		Path:      path,
+		Options:   options,
+		Data:      upload.Fields,
+	}
+	value, err := c.execute(ctx, call, func(ctx context.Context, call *Call) (*Response, error) {
+		fields, ok := call.Data.(map[string]string)
+		if !ok && call.Data != nil {
+			return nil, errors.Errorf("%s Multipart form fields must be a map[string]string, not %T", requestID, call.Data)
+		}
+		upload.Fields = fields
+		return c.sendMultipart(ctx, call, upload, requestID)
+	})
+	if err != nil {
+		return err
 	}
-	defer r.Close()
 
-	// Write header
+	// Decode the data field
+	return c.parseResponseData(value.Data, result, requestID)
+}
+
+// sendMultipart encodes and sends a multipart/form-data request
+func (c *Client) sendMultipart(ctx context.Context, call *Call, upload *multipartUpload, requestID xid.ID) (*Response, error) {
+	// Write form fields, in a stable order
 	buffer := &bytes.Buffer{}
 	partWriter := multipart.NewWriter(buffer)
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition",
-		fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-			escapeQuotes(field), escapeQuotes(filename)))
-	h.Set("Content-Type", contentType)
+	names := make([]string, 0, len(upload.Fields))
+	for name := range upload.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := partWriter.WriteField(name, upload.Fields[name]); err != nil {
+			return nil, errors.Wrapf(err, "%s write multipart field %s", requestID, name)
+		}
+	}
 
-	_, err = partWriter.CreatePart(h)
-	if err != nil {
-		return errors.Wrapf(err, "%s create multipart header", requestID)
+	// Write file header
+	if upload.Reader != nil {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition",
+			fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+				escapeQuotes(upload.FileField), escapeQuotes(upload.FileName)))
+		h.Set("Content-Type", upload.ContentType)
+
+		if _, err := partWriter.CreatePart(h); err != nil {
+			return nil, errors.Wrapf(err, "%s create multipart header", requestID)
+		}
 	}
 	headerSize := buffer.Len()
 
 	// Write footer
-	if err = partWriter.Close(); err != nil {
-		return errors.Wrapf(err, "%s create multipart footer", requestID)
+	if err := partWriter.Close(); err != nil {
+		return nil, errors.Wrapf(err, "%s create multipart footer", requestID)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	header := buffer.Bytes()[:headerSize]
+	footer := buffer.Bytes()[headerSize:]
+	body := func(file io.Reader) io.Reader {
+		if file == nil {
+			return io.MultiReader(bytes.NewReader(header), bytes.NewReader(footer))
+		}
+		return io.MultiReader(bytes.NewReader(header), file, bytes.NewReader(footer))
+	}
 
 	// Create request
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.getURL(path), io.MultiReader(
-		bytes.NewReader(buffer.Bytes()[:headerSize]),
-		r,
-		bytes.NewReader(buffer.Bytes()[headerSize:])))
+	var file io.Reader
+	if upload.Reader != nil {
+		file = upload.Reader
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.getURL(call.Path), body(file))
 	if err != nil {
-		return errors.Wrapf(err, "%s Request error", requestID)
+		return nil, errors.Wrapf(err, "%s Request error", requestID)
+	}
+
+	// Send an exact Content-Length when the file size is known, and allow the
+	// body to be recreated when the file can be rewound
+	switch {
+	case upload.Reader == nil:
+		request.ContentLength = int64(buffer.Len())
+		request.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(body(nil)), nil
+		}
+	case upload.Size >= 0:
+		request.ContentLength = int64(buffer.Len()) + upload.Size
+		if upload.Rewind != nil {
+			request.GetBody = func() (io.ReadCloser, error) {
+				f, err := upload.Rewind()
+				if err != nil {
+					return nil, err
+				}
+				return &readCloser{Reader: body(f), Closer: f}, nil
+			}
+		}
 	}
 
 	request.Header.Add("Content-Type", partWriter.FormDataContentType())
-	c.addHeaders(request, options)
-	resp, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return errors.Wrapf(err, "%s POST error", requestID)
+	c.addHeaders(request, call.Options)
+	if IsTrue(call.Options.Debug) {
+		fields := make(http.Header, len(upload.Fields))
+		for name, value := range upload.Fields {
+			fields[name] = []string{value}
+		}
+		c.dump(ctx, call.Options, requestID, "POST multipart "+call.Path, headersAttr("fields", fields),
+			slog.String("file_field", upload.FileField), slog.String("file_name", upload.FileName),
+			slog.String("content_type", upload.ContentType), slog.Int64("size", upload.Size))
 	}
 
-	_, err = c.parseResponse(resp, result, requestID, options)
-	return err
+	return c.roundTrip(request, requestID, call.Options)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
-func (c *Client) parseResponse(resp *http.Response, result interface{}, requestID xid.ID, options *Options) (*Response, error) {
+func (c *Client) parseResponse(resp *http.Response, requestID xid.ID, options *Options) (*Response, error) {
 
 	// Get response body
 	defer resp.Body.Close()
@@ -355,13 +584,8 @@ func (c *Client) parseResponse(resp *http.Response, result interface{}, requestI
 		return nil, err
 	}
 
-	if IsTrue(options.Debug) {
-		err := resp.Header.Write(os.Stderr)
-		if err != nil {
-			return nil, err
-		}
-		fmt.Fprintf(os.Stderr, "%s %s\n%s\n", requestID, resp.Status, body)
-	}
+	c.dump(context.Background(), options, requestID, "Response "+resp.Status,
+		headersAttr("headers", resp.Header), bodyAttr("body", body))
 
 	// Decode the response
 	value := &Response{}
@@ -387,7 +611,7 @@ func (c *Client) parseResponse(resp *http.Response, result interface{}, requestI
 		return nil, errors.Errorf("%s Missing data from response", requestID)
 	}
 
-	return value, c.parseResponseData(value.Data, result, requestID)
+	return value, nil
 }
 
 func (c *Client) parseResponseData(data []byte, result interface{}, requestID xid.ID) error {
@@ -409,3 +633,7 @@ func IsTrue(value *bool) bool {
 func Bool(value bool) *bool {
 	return &value
 }
+
+func String(value string) *string {
+	return &value
+}
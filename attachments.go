@@ -1,10 +1,19 @@
 package asana
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"github.com/pkg/errors"
 	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/pkg/errors"
+	"github.com/timwehrle/asana-api/richtext"
 )
 
 // Attachment represents any file attached to a task in Asana,
@@ -44,7 +53,7 @@ type Attachment struct {
 	// asana, dropbox, gdrive, box, and vimeo.
 	Host string `json:"host,omitempty"`
 
-	// Read-only. The task this object is attached to.
+	// Read-only. The task, project or project brief this object is attached to.
 	Parent *Task `json:"parent,omitempty"`
 
 	// Undocumented. A permanent asana.com link which should be a permalink
@@ -63,6 +72,167 @@ func (a *Attachment) GetID() string {
 	return a.ID
 }
 
+// Fetch loads the full details for this Attachment, including a fresh
+// DownloadURL
+func (a *Attachment) Fetch(client *Client, options ...*Options) error {
+	return a.FetchContext(context.Background(), client, options...)
+}
+
+// FetchContext is Fetch, stopping early if ctx is done
+func (a *Attachment) FetchContext(ctx context.Context, client *Client, options ...*Options) error {
+	client.trace("Loading details for attachment %q", a.Name)
+
+	_, err := client.getContext(ctx, fmt.Sprintf("/attachments/%s", a.ID), nil, a, options...)
+	return err
+}
+
+// Delete removes this attachment from its parent
+func (a *Attachment) Delete(client *Client) error {
+	client.info("Deleting attachment %q", a.Name)
+
+	return client.delete(fmt.Sprintf("/attachments/%s", a.ID))
+}
+
+// downloadAttempts is the number of times Download will start or resume a
+// transfer before giving up
+const downloadAttempts = 5
+
+// Download streams the contents of this attachment to w and returns the
+// number of bytes written.
+//
+// The DownloadURL is loaded with Fetch when it is missing, and refreshed once
+// if the storage host reports that it has expired. When the transfer is
+// interrupted it is resumed with a Range request from the last byte written.
+// A partial response which does not start at that byte is discarded and the
+// transfer restarted from the beginning, skipping the bytes already written.
+// If Size is known, the number of bytes written is checked against it.
+func (a *Attachment) Download(ctx context.Context, client *Client, w io.Writer) (int64, error) {
+	client.trace("Downloading attachment %q", a.Name)
+
+	if a.DownloadURL == "" {
+		if err := a.FetchContext(ctx, client); err != nil {
+			return 0, errors.Wrap(err, "Download attachment")
+		}
+		if a.DownloadURL == "" {
+			return 0, errors.Errorf("Download attachment: %s has no download URL", a.ID)
+		}
+	}
+
+	httpClient := client.DownloadClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	dest := &countingWriter{w: w}
+	refreshed := false
+	resume := true
+	var lastErr error
+
+	for attempt := 0; attempt < downloadAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return dest.n, err
+		}
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, a.DownloadURL, nil)
+		if err != nil {
+			return dest.n, errors.Wrap(err, "Download attachment")
+		}
+		if dest.n > 0 && resume {
+			request.Header.Set("Range", fmt.Sprintf("bytes=%d-", dest.n))
+		}
+
+		resp, err := httpClient.Do(request)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusForbidden && !refreshed:
+			// Pre-signed URLs expire after about an hour
+			resp.Body.Close()
+			refreshed = true
+			attempt--
+			if err := a.FetchContext(ctx, client); err != nil {
+				return dest.n, errors.Wrap(err, "Refresh download URL")
+			}
+			continue
+		case resp.StatusCode == http.StatusPartialContent && dest.n > 0:
+			if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != dest.n {
+				// The range is misaligned, so download the whole file again
+				resp.Body.Close()
+				resume = false
+				lastErr = errors.Errorf("partial content from %q instead of byte %d", resp.Header.Get("Content-Range"), dest.n)
+				continue
+			}
+		case resp.StatusCode == http.StatusOK:
+			// The server ignored the Range header, so skip what we already have
+			if dest.n > 0 {
+				if _, err := io.CopyN(io.Discard, resp.Body, dest.n); err != nil {
+					resp.Body.Close()
+					lastErr = err
+					continue
+				}
+			}
+		default:
+			resp.Body.Close()
+			return dest.n, errors.Errorf("Download attachment: unexpected status %s", resp.Status)
+		}
+
+		_, err = io.Copy(dest, resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			return dest.n, a.verifySize(dest.n)
+		}
+		if dest.err != nil {
+			// Failures writing to the destination cannot be resumed
+			return dest.n, errors.Wrap(dest.err, "Download attachment")
+		}
+		lastErr = err
+	}
+
+	return dest.n, errors.Wrapf(lastErr, "Download attachment: gave up after %d attempts", downloadAttempts)
+}
+
+// contentRangeStart returns the first byte of a Content-Range header such
+// as "bytes 100-199/200"
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+func (a *Attachment) verifySize(n int64) error {
+	if a.Size != nil && int64(*a.Size) != n {
+		return errors.Errorf("Download attachment: expected %d bytes but received %d", *a.Size, n)
+	}
+	return nil
+}
+
+// countingWriter records how much has been written, and any write error, so
+// that a download can be resumed from the right offset
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
+
 // Attachments lists all attachments attached to a task
 func (t *Task) Attachments(client *Client, opts ...*Options) ([]*Attachment, *NextPage, error) {
 	client.trace("Listing attachments for %q", t.Name)
@@ -74,23 +244,280 @@ func (t *Task) Attachments(client *Client, opts ...*Options) ([]*Attachment, *Ne
 	return result, nextPage, err
 }
 
+// Attachments lists all attachments on a task, project or project brief
+func (c *Client) Attachments(parent string, opts ...*Options) ([]*Attachment, *NextPage, error) {
+	c.trace("Listing attachments for %s", parent)
+
+	var result []*Attachment
+
+	// Make the request
+	query := attachmentsRequestParams{
+		Parent: parent,
+	}
+	nextPage, err := c.get("/attachments", query, &result, opts...)
+	return result, nextPage, err
+}
+
+// Attachments lists all attachments attached to a project
+func (p *Project) Attachments(client *Client, opts ...*Options) ([]*Attachment, *NextPage, error) {
+	return client.Attachments(p.ID, opts...)
+}
+
+type attachmentsRequestParams struct {
+	Parent string `url:"parent"`
+}
+
+// MaxAttachmentSize is the largest file, in bytes, that the API accepts as
+// an attachment
+const MaxAttachmentSize = 100 * 1024 * 1024
+
+// ErrAttachmentTooLarge is returned before uploading a file which is known to
+// be larger than MaxAttachmentSize
+var ErrAttachmentTooLarge = errors.New("attachment exceeds the 100MB upload limit")
+
+// NewAttachment describes a file to upload as an attachment
 type NewAttachment struct {
-	Reader      io.ReadCloser
-	FileName    string
+	Reader   io.ReadCloser
+	FileName string
+
+	// The MIME type of the file. When empty it is guessed from the file name
+	// extension, or failing that from the first 512 bytes of the content.
 	ContentType string
+
+	// Optional. The size of the file in bytes. When zero it is measured by
+	// seeking if Reader is an io.Seeker such as an *os.File, or taken from a
+	// Size method such as that of io.SectionReader. Files of known size are
+	// sent with an exact Content-Length and checked against
+	// MaxAttachmentSize before anything is uploaded.
+	Size int64
+
+	// Optional. Reopen returns a new reader for the file from its start so
+	// that the upload can be retried. It is not needed when Reader is an
+	// io.Seeker, or an io.ReaderAt of known size.
+	Reopen func() (io.ReadCloser, error)
+
+	// Optional. Called as the upload proceeds with the number of bytes of the
+	// file sent so far.
+	Progress func(sent int64)
 }
 
-func (t *Task) CreateAttachment(client *Client, request *NewAttachment) (*Attachment, error) {
-	client.trace("Uploading attachment for %q", t.Name)
+// size returns the number of bytes remaining in the file, or -1 if that
+// cannot be determined
+func (n *NewAttachment) size() (int64, error) {
+	if n.Size > 0 {
+		return n.Size, nil
+	}
+
+	if s, ok := n.Reader.(io.Seeker); ok {
+		// Seeking fails on pipes and sockets, which have no known size
+		if start, err := s.Seek(0, io.SeekCurrent); err == nil {
+			end, err := s.Seek(0, io.SeekEnd)
+			if err != nil {
+				return -1, err
+			}
+			if _, err := s.Seek(start, io.SeekStart); err != nil {
+				return -1, err
+			}
+			return end - start, nil
+		}
+	}
+
+	if s, ok := n.Reader.(interface{ Size() int64 }); ok {
+		return s.Size(), nil
+	}
+
+	return -1, nil
+}
+
+// rewinder returns a function which provides the file content from the start
+// again, or nil if the content can only be read once
+func (n *NewAttachment) rewinder(size int64) func() (io.ReadCloser, error) {
+	if n.Reopen != nil {
+		return n.Reopen
+	}
+
+	if s, ok := n.Reader.(io.ReadSeeker); ok {
+		if start, err := s.Seek(0, io.SeekCurrent); err == nil {
+			return func() (io.ReadCloser, error) {
+				if _, err := s.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				// The original reader is closed once the request completes
+				return io.NopCloser(s), nil
+			}
+		}
+	}
+
+	if r, ok := n.Reader.(io.ReaderAt); ok && size >= 0 {
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(r, 0, size)), nil
+		}
+	}
+
+	return nil
+}
+
+// contentType returns the declared content type of the upload, detecting it
+// if necessary. Detection of a reader which cannot seek replaces Reader with
+// a buffered reader.
+func (n *NewAttachment) contentType() (string, error) {
+	if n.ContentType != "" {
+		return n.ContentType, nil
+	}
+
+	if t := mime.TypeByExtension(filepath.Ext(n.FileName)); t != "" {
+		return t, nil
+	}
+
+	if s, ok := n.Reader.(io.ReadSeeker); ok {
+		if start, err := s.Seek(0, io.SeekCurrent); err == nil {
+			head := make([]byte, 512)
+			read, err := io.ReadFull(s, head)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return "", err
+			}
+			if _, err := s.Seek(start, io.SeekStart); err != nil {
+				return "", err
+			}
+			return http.DetectContentType(head[:read]), nil
+		}
+	}
+
+	buffered := &bufferedReadCloser{Reader: bufio.NewReaderSize(n.Reader, 512), Closer: n.Reader}
+	n.Reader = buffered
+
+	// Peek returns what it could read along with any error, which will be
+	// reported again when the body is streamed
+	head, _ := buffered.Peek(512)
+	return http.DetectContentType(head), nil
+}
+
+type bufferedReadCloser struct {
+	*bufio.Reader
+	io.Closer
+}
+
+// progressReader reports the running total of bytes read to a callback
+type progressReader struct {
+	io.ReadCloser
+	sent     int64
+	progress func(sent int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent)
+	}
+	return n, err
+}
+
+// CreateAttachment uploads a file as an attachment to a task, project or
+// project brief.
+//
+// If the size of the file is known and exceeds MaxAttachmentSize, the upload
+// is not attempted and ErrAttachmentTooLarge is returned.
+func (c *Client) CreateAttachment(parent string, request *NewAttachment) (*Attachment, error) {
+	return c.CreateAttachmentContext(context.Background(), parent, request)
+}
+
+// CreateAttachmentContext is CreateAttachment, stopping the upload if ctx is
+// done first
+func (c *Client) CreateAttachmentContext(ctx context.Context, parent string, request *NewAttachment) (*Attachment, error) {
+	c.trace("Uploading attachment %q to %s", request.FileName, parent)
+
+	size, err := request.size()
+	if err != nil {
+		request.Reader.Close()
+		return nil, errors.Wrap(err, "Measure attachment")
+	}
+	if size > MaxAttachmentSize {
+		request.Reader.Close()
+		return nil, errors.Wrapf(ErrAttachmentTooLarge, "Upload attachment %q of %d bytes", request.FileName, size)
+	}
+
+	rewind := request.rewinder(size)
+
+	contentType, err := request.contentType()
+	if err != nil {
+		request.Reader.Close()
+		return nil, errors.Wrap(err, "Detect attachment content type")
+	}
+
+	reader := request.Reader
+	if request.Progress != nil {
+		reader = &progressReader{ReadCloser: reader, progress: request.Progress}
+		if rewind != nil {
+			reopen := rewind
+			rewind = func() (io.ReadCloser, error) {
+				r, err := reopen()
+				if err != nil {
+					return nil, err
+				}
+				return &progressReader{ReadCloser: r, progress: request.Progress}, nil
+			}
+		}
+	}
+
+	upload := &multipartUpload{
+		Fields: map[string]string{
+			"parent":           parent,
+			"resource_subtype": "asana",
+		},
+		FileField:   "file",
+		Reader:      reader,
+		FileName:    request.FileName,
+		ContentType: contentType,
+		Size:        size,
+		Rewind:      rewind,
+	}
 
 	result := &Attachment{}
-	err := client.postMultipart(fmt.Sprintf("/tasks/%s/attachments", t.ID), result, "file", request.Reader, request.FileName, request.ContentType)
+	err = c.postMultipart(ctx, "/attachments", upload, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "Upload attachment")
 	}
 	return result, nil
 }
 
+// CreateAttachment uploads a file as an attachment to this task
+func (t *Task) CreateAttachment(client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachment(t.ID, request)
+}
+
+// CreateAttachmentContext uploads a file as an attachment to this task,
+// stopping the upload if ctx is done first
+func (t *Task) CreateAttachmentContext(ctx context.Context, client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachmentContext(ctx, t.ID, request)
+}
+
+// CreateAttachment uploads a file as an attachment to this project
+func (p *Project) CreateAttachment(client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachment(p.ID, request)
+}
+
+// CreateAttachmentContext uploads a file as an attachment to this project,
+// stopping the upload if ctx is done first
+func (p *Project) CreateAttachmentContext(ctx context.Context, client *Client, request *NewAttachment) (*Attachment, error) {
+	return client.CreateAttachmentContext(ctx, p.ID, request)
+}
+
+// CreateInlineComment uploads a file to this task and adds a comment which
+// shows it inline. The optional text is placed before the file in the
+// comment.
+func (t *Task) CreateInlineComment(client *Client, request *NewAttachment, text string) (*Story, error) {
+	client.info("Creating inline attachment comment for task %q", t.Name)
+
+	attachment, err := t.CreateAttachment(client, request)
+	if err != nil {
+		return nil, err
+	}
+
+	body := richtext.Body(richtext.Text(text), richtext.Image(attachment.ID))
+	return t.CreateComment(client, &StoryBase{HTMLText: body.HTML()})
+}
+
 type ExternalAttachmentRequest struct {
 	ConnectToApp    *bool  `json:"connect_to_app,omitempty"`
 	Name            string `json:"name"`
@@ -98,14 +525,43 @@ type ExternalAttachmentRequest struct {
 	ResourceSubtype string `json:"resource_subtype"`
 }
 
-func (t *Task) CreateExternalAttachment(client *Client, request *ExternalAttachmentRequest) (*Attachment, error) {
-	client.trace("Creating external attachment for %q", t.Name)
+// CreateExternalAttachment links an external resource by URL as an
+// attachment on a task, project or project brief. Setting ConnectToApp shows
+// the attachment in the app components widget of the calling app.
+func (c *Client) CreateExternalAttachment(parent string, request *ExternalAttachmentRequest) (*Attachment, error) {
+	return c.CreateExternalAttachmentContext(context.Background(), parent, request)
+}
+
+// CreateExternalAttachmentContext is CreateExternalAttachment, stopping if
+// ctx is done first
+func (c *Client) CreateExternalAttachmentContext(ctx context.Context, parent string, request *ExternalAttachmentRequest) (*Attachment, error) {
+	c.trace("Creating external attachment %q for %s", request.Name, parent)
 	request.ResourceSubtype = "external"
 
+	fields := map[string]string{
+		"parent":           parent,
+		"resource_subtype": request.ResourceSubtype,
+		"name":             request.Name,
+		"url":              request.URL,
+	}
+	if request.ConnectToApp != nil {
+		fields["connect_to_app"] = strconv.FormatBool(*request.ConnectToApp)
+	}
+
 	result := &Attachment{}
-	err := client.post(fmt.Sprintf("/tasks/%s/attachments", t.ID), request, result)
+	err := c.postMultipart(ctx, "/attachments", &multipartUpload{Fields: fields}, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "Create external attachment")
 	}
 	return result, nil
 }
+
+func (t *Task) CreateExternalAttachment(client *Client, request *ExternalAttachmentRequest) (*Attachment, error) {
+	return client.CreateExternalAttachment(t.ID, request)
+}
+
+// CreateExternalAttachmentContext links an external resource by URL as an
+// attachment on this task, stopping if ctx is done first
+func (t *Task) CreateExternalAttachmentContext(ctx context.Context, client *Client, request *ExternalAttachmentRequest) (*Attachment, error) {
+	return client.CreateExternalAttachmentContext(ctx, t.ID, request)
+}
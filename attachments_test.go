@@ -0,0 +1,323 @@
+package asana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// brokenReader returns its data followed by a non-EOF error
+type brokenReader struct {
+	r io.Reader
+}
+
+func (b *brokenReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func (b *brokenReader) Close() error { return nil }
+
+func TestAttachment_Download_Resume(t *testing.T) {
+	content := "hello, attachment"
+	var ranges []string
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	client.DownloadClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		if len(ranges) == 1 {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &brokenReader{r: strings.NewReader(content[:5])},
+				Header:     make(http.Header),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusPartialContent,
+			Body:       io.NopCloser(strings.NewReader(content[5:])),
+			Header:     http.Header{"Content-Range": {fmt.Sprintf("bytes 5-%d/%d", len(content)-1, len(content))}},
+		}, nil
+	})}
+
+	size := len(content)
+	a := &Attachment{ID: "1", DownloadURL: "https://example.com/file", Size: &size}
+
+	buf := &bytes.Buffer{}
+	n, err := a.Download(context.Background(), client, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != int64(size) || buf.String() != content {
+		t.Errorf("Expected %q but downloaded %q (%d bytes)", content, buf.String(), n)
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=5-" {
+		t.Errorf("Unexpected Range headers %q", ranges)
+	}
+}
+
+func TestAttachment_Download_MisalignedRange(t *testing.T) {
+	content := "hello, attachment"
+	var ranges []string
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	client.DownloadClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		switch len(ranges) {
+		case 1:
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &brokenReader{r: strings.NewReader(content[:5])},
+				Header:     make(http.Header),
+			}, nil
+		case 2:
+			// Partial content which starts in the wrong place
+			return &http.Response{
+				StatusCode: http.StatusPartialContent,
+				Body:       io.NopCloser(strings.NewReader(content[2:])),
+				Header:     http.Header{"Content-Range": {fmt.Sprintf("bytes 2-%d/%d", len(content)-1, len(content))}},
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(content)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	a := &Attachment{ID: "1", DownloadURL: "https://example.com/file"}
+
+	buf := &bytes.Buffer{}
+	if _, err := a.Download(context.Background(), client, buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != content {
+		t.Errorf("Expected %q but downloaded %q", content, buf.String())
+	}
+	if len(ranges) != 3 || ranges[1] != "bytes=5-" || ranges[2] != "" {
+		t.Errorf("Expected a full restart after the misaligned range but saw %q", ranges)
+	}
+}
+
+func TestContentRangeStart(t *testing.T) {
+	for header, expected := range map[string]int64{
+		"bytes 100-199/200": 100,
+		"bytes 0-0/*":       0,
+		"bytes */200":       -1,
+		"":                  -1,
+	} {
+		start, ok := contentRangeStart(header)
+		if !ok {
+			start = -1
+		}
+		if start != expected {
+			t.Errorf("Expected %q to start at %d but saw %d", header, expected, start)
+		}
+	}
+}
+
+func TestAttachment_Download_SizeMismatch(t *testing.T) {
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	client.DownloadClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("short")),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	size := 100
+	a := &Attachment{ID: "1", DownloadURL: "https://example.com/file", Size: &size}
+
+	if _, err := a.Download(context.Background(), client, io.Discard); err == nil {
+		t.Error("Expected a size mismatch error")
+	}
+}
+
+func TestClient_CreateAttachment(t *testing.T) {
+	var form *multipart.Form
+	client, _ := NewClient(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/1.0/attachments" {
+			t.Errorf("Unexpected path %s", req.URL.Path)
+		}
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		form = req.MultipartForm
+		return MockResponse(http.StatusOK, o{"gid": "987"})
+	})))
+
+	var progress int64
+	a, err := client.CreateAttachment("123", &NewAttachment{
+		Reader:   io.NopCloser(strings.NewReader("some notes")),
+		FileName: "notes.txt",
+		Progress: func(sent int64) { progress = sent },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.ID != "987" {
+		t.Errorf("Expected attachment ID 987 but saw %s", a.ID)
+	}
+	if got := form.Value["parent"]; len(got) != 1 || got[0] != "123" {
+		t.Errorf("Expected parent 123 but saw %v", got)
+	}
+	files := form.File["file"]
+	if len(files) != 1 || files[0].Filename != "notes.txt" {
+		t.Fatalf("Unexpected file parts %v", files)
+	}
+	if ct := files[0].Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected a detected text/plain content type but saw %q", ct)
+	}
+	if progress != int64(len("some notes")) {
+		t.Errorf("Expected progress to reach %d but saw %d", len("some notes"), progress)
+	}
+}
+
+func TestClient_CreateAttachment_ContentLength(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 4096)
+
+	var contentLength int64
+	var body, expected []byte
+	client, _ := NewClient(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		contentLength = req.ContentLength
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			t.Fatal(err)
+		}
+		if req.GetBody == nil {
+			t.Error("Expected a seekable upload to be retryable")
+		}
+
+		// Build the same form independently, using the request's boundary
+		_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buffer := &bytes.Buffer{}
+		w := multipart.NewWriter(buffer)
+		w.SetBoundary(params["boundary"])
+		w.WriteField("parent", "123")
+		w.WriteField("resource_subtype", "asana")
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="file"; filename="data.bin"`)
+		h.Set("Content-Type", "application/octet-stream")
+		part, _ := w.CreatePart(h)
+		part.Write(content)
+		w.Close()
+		expected = buffer.Bytes()
+
+		return MockResponse(http.StatusOK, o{"gid": "987"})
+	})))
+
+	_, err := client.CreateAttachment("123", &NewAttachment{
+		Reader:      &seekCloser{bytes.NewReader(content)},
+		FileName:    "data.bin",
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentLength != int64(len(expected)) {
+		t.Errorf("Expected a Content-Length of %d but saw %d", len(expected), contentLength)
+	}
+	if !bytes.Equal(body, expected) {
+		t.Errorf("Expected body\n%q\nbut saw\n%q", expected, body)
+	}
+}
+
+func TestClient_CreateAttachment_Timeout(t *testing.T) {
+	var deadline bool
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, deadline = req.Context().Deadline()
+		return MockResponse(http.StatusOK, o{"gid": "987"})
+	})
+	upload := func(client *Client) {
+		_, err := client.CreateAttachment("123", &NewAttachment{
+			Reader:   io.NopCloser(strings.NewReader("notes")),
+			FileName: "notes.txt",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client, _ := NewClient(WithTransport(transport), WithTimeout(time.Second))
+	upload(client)
+	if deadline {
+		t.Error("Expected uploads to have no deadline by default")
+	}
+
+	client, _ = NewClient(WithTransport(transport), WithUploadTimeout(time.Minute))
+	upload(client)
+	if !deadline {
+		t.Error("Expected the upload timeout to set a deadline")
+	}
+}
+
+func TestClient_CreateAttachmentContext(t *testing.T) {
+	client, _ := NewClient(
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})),
+		WithUploadTimeout(time.Minute),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := (&Task{ID: "123"}).CreateAttachmentContext(ctx, client, &NewAttachment{
+		Reader:   io.NopCloser(strings.NewReader("notes")),
+		FileName: "notes.txt",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context's deadline to stop the upload but saw %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("Expected the upload to stop at the context's deadline")
+	}
+}
+
+func TestClient_CreateAttachment_TooLarge(t *testing.T) {
+	client, _ := NewClient(WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("Expected no request for an oversized attachment")
+		return MockResponse(http.StatusOK, o{})
+	})))
+
+	_, err := client.CreateAttachment("123", &NewAttachment{
+		Reader:   io.NopCloser(strings.NewReader("")),
+		FileName: "huge.bin",
+		Size:     MaxAttachmentSize + 1,
+	})
+	if !IsPayloadTooLarge(err) {
+		t.Errorf("Expected a payload too large error but saw %v", err)
+	}
+}
+
+type seekCloser struct {
+	*bytes.Reader
+}
+
+func (s *seekCloser) Close() error { return nil }
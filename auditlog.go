@@ -0,0 +1,333 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditLogActorType identifies who performed an audited action
+type AuditLogActorType string
+
+// Actor types of audit log events
+const (
+	AuditLogActorUser                  AuditLogActorType = "user"
+	AuditLogActorAsana                 AuditLogActorType = "asana"
+	AuditLogActorAsanaSupport          AuditLogActorType = "asana_support"
+	AuditLogActorAnonymous             AuditLogActorType = "anonymous"
+	AuditLogActorExternalAdministrator AuditLogActorType = "external_administrator"
+)
+
+// AuditLogActor is the entity that triggered an audit log event
+type AuditLogActor struct {
+	ActorType AuditLogActorType `json:"actor_type,omitempty"`
+
+	// The ID, name and email address of the user, if the actor is a user
+	ID    string `json:"gid,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// AuditLogResource is the object affected by an audit log event
+type AuditLogResource struct {
+	ID              string `json:"gid,omitempty"`
+	ResourceType    string `json:"resource_type,omitempty"`
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+	Name            string `json:"name,omitempty"`
+
+	// The email address of the resource, if it is a user
+	Email string `json:"email,omitempty"`
+}
+
+// AuditLogContext describes the circumstances in which an audit log event
+// was triggered
+type AuditLogContext struct {
+	// One of web, desktop, mobile, asana_support, asana, email, api
+	ContextType string `json:"context_type,omitempty"`
+
+	// How an API request was authenticated: personal_access_token, oauth or
+	// service_account
+	APIAuthenticationMethod string `json:"api_authentication_method,omitempty"`
+
+	ClientIPAddress string `json:"client_ip_address,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+
+	// The name of the OAuth app which made the request, if any
+	OAuthAppName string `json:"oauth_app_name,omitempty"`
+
+	// The name of the rule which triggered the event, if any
+	RuleName string `json:"rule_name,omitempty"`
+}
+
+// AuditLogEventDetails holds event specific information. The fields which
+// are common to many event types are decoded; the full details are kept in
+// Raw and can be decoded into a custom type with Decode.
+type AuditLogEventDetails struct {
+	OldValue any `json:"old_value,omitempty"`
+	NewValue any `json:"new_value,omitempty"`
+
+	// The group, such as a team, the event relates to
+	Group *AuditLogResource `json:"group,omitempty"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the common fields and keeps a copy of the raw
+// details
+func (d *AuditLogEventDetails) UnmarshalJSON(data []byte) error {
+	type details AuditLogEventDetails
+	if err := json.Unmarshal(data, (*details)(d)); err != nil {
+		return err
+	}
+	d.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON encodes the raw details when they are known
+func (d AuditLogEventDetails) MarshalJSON() ([]byte, error) {
+	if d.Raw != nil {
+		return d.Raw, nil
+	}
+	type details AuditLogEventDetails
+	return json.Marshal(details(d))
+}
+
+// Decode unmarshals the raw details into v
+func (d *AuditLogEventDetails) Decode(v any) error {
+	if d.Raw == nil {
+		return nil
+	}
+	return json.Unmarshal(d.Raw, v)
+}
+
+// AuditLogEvent is a single action recorded in the audit log of an
+// Enterprise domain
+type AuditLogEvent struct {
+	// Read-only. Globally unique ID of the event
+	ID string `json:"gid,omitempty"`
+
+	// The time the event was created
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// The type of the event, such as task_deleted or user_login_succeeded
+	EventType string `json:"event_type,omitempty"`
+
+	// The category of the event, such as logins or access_control
+	EventCategory string `json:"event_category,omitempty"`
+
+	Actor    *AuditLogActor        `json:"actor,omitempty"`
+	Resource *AuditLogResource     `json:"resource,omitempty"`
+	Context  *AuditLogContext      `json:"context,omitempty"`
+	Details  *AuditLogEventDetails `json:"details,omitempty"`
+}
+
+// AuditLogQuery filters the events returned from the audit log. All fields
+// are optional.
+type AuditLogQuery struct {
+	// Only events created at or after this time
+	StartAt *time.Time `url:"start_at,omitempty"`
+
+	// Only events created before this time
+	EndAt *time.Time `url:"end_at,omitempty"`
+
+	// Only events of this type
+	EventType string `url:"event_type,omitempty"`
+
+	// Only events triggered by this type of actor
+	ActorType AuditLogActorType `url:"actor_type,omitempty"`
+
+	// Only events triggered by the actor with this ID
+	ActorID string `url:"actor_gid,omitempty"`
+
+	// Only events affecting the resource with this ID
+	ResourceID string `url:"resource_gid,omitempty"`
+}
+
+// Validate checks the query before it is sent
+func (q *AuditLogQuery) Validate() error {
+	if q.StartAt != nil && q.EndAt != nil && !q.EndAt.After(*q.StartAt) {
+		return errors.New("Audit log query EndAt must be after StartAt")
+	}
+	if q.ActorID != "" && q.ActorType != "" && q.ActorType != AuditLogActorUser {
+		return errors.Errorf("Audit log query ActorID can only be used with actor type %q", AuditLogActorUser)
+	}
+	return nil
+}
+
+// AuditLogIterator pages through audit log events. It is used in the same
+// way as bufio.Scanner:
+//
+//	events := workspace.AuditLogEvents(client, query)
+//	for events.Next() {
+//		event := events.Event()
+//		...
+//	}
+//	if err := events.Err(); err != nil {
+//		...
+//	}
+type AuditLogIterator struct {
+	ctx       context.Context
+	client    *Client
+	workspace *Workspace
+	query     *AuditLogQuery
+	options   []*Options
+
+	// The offset used to fetch the buffered events, and the offset of the
+	// page after them
+	pageOffset string
+	offset     string
+
+	events   []*AuditLogEvent
+	event    *AuditLogEvent
+	caughtUp bool
+	err      error
+
+	// The IDs of the events already fetched from seenOffset. A page which
+	// is not followed by another does not advance the offset, so fetching
+	// it again returns the same events before any new ones.
+	seenOffset string
+	seen       map[string]bool
+}
+
+// AuditLogEvents returns an iterator over the audit log events of this
+// workspace, which must be an Enterprise organization. Pass an Offset in the
+// options to resume from one previously returned by the iterator's Offset
+// method. A nil query returns all events.
+func (w *Workspace) AuditLogEvents(client *Client, query *AuditLogQuery, options ...*Options) *AuditLogIterator {
+	return w.AuditLogEventsContext(context.Background(), client, query, options...)
+}
+
+// AuditLogEventsContext is AuditLogEvents, stopping the iteration with the
+// context's error once ctx is done
+func (w *Workspace) AuditLogEventsContext(ctx context.Context, client *Client, query *AuditLogQuery, options ...*Options) *AuditLogIterator {
+	if query == nil {
+		query = &AuditLogQuery{}
+	}
+
+	it := &AuditLogIterator{
+		ctx:       ctx,
+		client:    client,
+		workspace: w,
+		query:     query,
+	}
+	for _, o := range options {
+		if o.Offset != "" {
+			it.offset = o.Offset
+		}
+		it.options = append(it.options, o)
+	}
+	it.pageOffset = it.offset
+	return it
+}
+
+// Next advances to the next event, fetching another page when needed. It
+// returns false when there are no more events or an error occurs.
+func (it *AuditLogIterator) Next() bool {
+	for len(it.events) == 0 {
+		if it.err != nil || it.caughtUp {
+			it.event = nil
+			return false
+		}
+		it.fetch()
+	}
+
+	it.event = it.events[0]
+	it.events = it.events[1:]
+	return true
+}
+
+func (it *AuditLogIterator) fetch() {
+	it.client.trace("Listing audit log events in workspace %q from offset %q...\n", it.workspace.Name, it.offset)
+
+	opts := append([]*Options{{Limit: 100}}, it.options...)
+	if it.offset != "" {
+		opts = append(opts, &Options{Offset: it.offset})
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+
+	var events []*AuditLogEvent
+	nextPage, err := it.client.getContext(it.ctx, fmt.Sprintf("/workspaces/%s/audit_log_events", it.workspace.ID), it.query, &events, opts...)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	// Skip the events already returned from this offset
+	if it.seen == nil || it.seenOffset != it.offset {
+		it.seenOffset = it.offset
+		it.seen = map[string]bool{}
+	}
+	it.events = nil
+	for _, event := range events {
+		if event.ID != "" && it.seen[event.ID] {
+			continue
+		}
+		it.seen[event.ID] = true
+		it.events = append(it.events, event)
+	}
+
+	// The audit log always returns an offset to support streaming, so an
+	// empty page marks the end of the events available so far
+	it.pageOffset = it.offset
+	if nextPage != nil {
+		it.offset = nextPage.Offset
+	}
+	it.caughtUp = len(events) == 0 || nextPage == nil
+}
+
+// Event returns the current event
+func (it *AuditLogIterator) Event() *AuditLogEvent {
+	return it.event
+}
+
+// Err returns the error, if any, which stopped the iteration
+func (it *AuditLogIterator) Err() error {
+	return it.err
+}
+
+// Offset returns the offset from which to resume without missing events
+// that have not yet been returned by Next. Events from the current page may
+// be returned again after resuming; use the event ID to discard them.
+func (it *AuditLogIterator) Offset() string {
+	if len(it.events) > 0 {
+		return it.pageOffset
+	}
+	return it.offset
+}
+
+// TailAuditLogEvents continuously follows the audit log of this workspace,
+// calling fn for each event along with the offset to store in order to
+// resume after it. Once all available events have been read the log is
+// polled again every interval, and events already passed to fn are not
+// passed again. It returns when ctx is done or fn or a request fails.
+func (w *Workspace) TailAuditLogEvents(ctx context.Context, client *Client, query *AuditLogQuery, offset string, interval time.Duration, fn func(event *AuditLogEvent, offset string) error) error {
+	it := w.AuditLogEventsContext(ctx, client, query, &Options{Offset: offset})
+
+	for {
+		for it.Next() {
+			if err := fn(it.Event(), it.Offset()); err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		it.caughtUp = false
+	}
+}
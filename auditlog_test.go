@@ -0,0 +1,118 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+)
+
+func TestWorkspace_AuditLogEvents(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com").
+		Get("/api/1.0/workspaces/1/audit_log_events").
+		MatchParam("event_type", "task_deleted").
+		MatchParam("offset", "a").
+		Reply(200).
+		JSON(o{
+			"data": []o{
+				{"gid": "e1", "event_type": "task_deleted", "actor": o{"actor_type": "user", "gid": "u1"}},
+				{"gid": "e2", "event_type": "task_deleted", "details": o{"old_value": "x", "extra": 1}},
+			},
+			"next_page": o{"offset": "b"},
+		})
+	gock.New("https://app.asana.com").
+		Get("/api/1.0/workspaces/1/audit_log_events").
+		MatchParam("offset", "b").
+		Reply(200).
+		JSON(o{"data": []o{}, "next_page": o{"offset": "c"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	workspace := &Workspace{ID: "1"}
+	events := workspace.AuditLogEvents(client, &AuditLogQuery{EventType: "task_deleted"}, &Options{Offset: "a"})
+
+	var ids []string
+	var offsets []string
+	var last *AuditLogEvent
+	for events.Next() {
+		last = events.Event()
+		ids = append(ids, last.ID)
+		offsets = append(offsets, events.Offset())
+	}
+	if err := events.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 2 || ids[0] != "e1" || ids[1] != "e2" {
+		t.Errorf("Unexpected events %v", ids)
+	}
+	if offsets[0] != "a" || offsets[1] != "b" {
+		t.Errorf("Unexpected resume offsets %v", offsets)
+	}
+	if events.Offset() != "c" {
+		t.Errorf("Expected to resume from offset c but saw %q", events.Offset())
+	}
+
+	var extra struct{ Extra int }
+	if err := last.Details.Decode(&extra); err != nil {
+		t.Fatal(err)
+	}
+	if last.Details.OldValue != "x" || extra.Extra != 1 {
+		t.Errorf("Unexpected details %+v", last.Details)
+	}
+}
+
+func TestWorkspace_TailAuditLogEvents(t *testing.T) {
+	defer gock.Off()
+
+	// The last page has no next page, so it is fetched again from the same
+	// offset and returns the events already seen along with a new one
+	gock.New("https://app.asana.com").
+		Get("/api/1.0/workspaces/1/audit_log_events").
+		MatchParam("offset", "a").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "e1"}, {"gid": "e2"}}})
+	gock.New("https://app.asana.com").
+		Get("/api/1.0/workspaces/1/audit_log_events").
+		MatchParam("offset", "a").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "e1"}, {"gid": "e2"}, {"gid": "e3"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	err := (&Workspace{ID: "1"}).TailAuditLogEvents(ctx, client, nil, "a", time.Millisecond, func(event *AuditLogEvent, offset string) error {
+		ids = append(ids, event.ID)
+		if event.ID == "e3" {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected the tail to be cancelled but saw %v", err)
+	}
+	if len(ids) != 3 || ids[2] != "e3" {
+		t.Errorf("Expected each event once but saw %v", ids)
+	}
+}
+
+func TestWorkspace_AuditLogEventsContext(t *testing.T) {
+	defer gock.Off()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	events := (&Workspace{ID: "1"}).AuditLogEventsContext(ctx, client, nil)
+	if events.Next() {
+		t.Error("Expected no events once the context is done")
+	}
+	if events.Err() != context.Canceled {
+		t.Errorf("Expected the context's error but saw %v", events.Err())
+	}
+}
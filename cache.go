@@ -0,0 +1,247 @@
+package asana
+
+import (
+	"container/list"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cache stores API responses for a read-through cache, see WithCache.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, unless it has expired
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key until ttl has passed
+	Set(key string, value []byte, ttl time.Duration)
+
+	// DeletePrefix removes every value whose key starts with prefix
+	DeletePrefix(prefix string)
+}
+
+// DefaultCacheTTL returns cache lifetimes suitable for resources which
+// rarely change: workspaces, teams, users, custom field definitions and
+// tags
+func DefaultCacheTTL() map[string]time.Duration {
+	return map[string]time.Duration{
+		"workspaces":    time.Hour,
+		"teams":         time.Hour,
+		"users":         15 * time.Minute,
+		"custom_fields": 15 * time.Minute,
+		"tags":          15 * time.Minute,
+	}
+}
+
+// WithCache caches the responses to GET requests in cache. ttl sets how long
+// responses are kept for each type of resource, named by the last part of
+// the API path which is not an ID: "users" for both /users/{gid} and
+// /workspaces/{gid}/users. Other resources are not cached.
+//
+// Cached responses are keyed by path, query parameters including the
+// requested fields, and enabled features. A PUT or DELETE of a resource
+// through the same client removes the cached responses for that resource
+// and the paths below it, and a POST to a list, such as creating a tag in
+// a workspace, removes the cached list. Adding a user to or removing one
+// from a workspace or team removes its cached lists of users and
+// memberships. Other lists which include the resource are not invalidated
+// and may be stale until they expire.
+//
+// The cache must not be shared between clients for different users, who
+// may see different data.
+func WithCache(cache Cache, ttl map[string]time.Duration) ClientOption {
+	return func(c *Client) error {
+		if cache == nil {
+			return errors.New("Cache must not be nil")
+		}
+		for _, d := range ttl {
+			if d <= 0 {
+				return errors.New("Cache TTL must be positive")
+			}
+		}
+		c.cache = cache
+		c.cacheTTL = ttl
+		return nil
+	}
+}
+
+// resourceType returns the last part of path which is not an ID
+func resourceType(path string) string {
+	segments := strings.Split(endpointTemplate(path), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		switch segments[i] {
+		case "", "{gid}", "me":
+		default:
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+// cacheKey identifies a GET request. Every key for path starts with
+// path + "?", so that they can be invalidated together.
+func cacheKey(path, query string, options *Options) string {
+	key := path + "?" + query
+	if len(options.Enable) > 0 {
+		key += "#enable=" + joinFeatures(options.Enable)
+	}
+	if len(options.Disable) > 0 {
+		key += "#disable=" + joinFeatures(options.Disable)
+	}
+	return key
+}
+
+// cacheTTLFor returns how long responses for path are cached, or zero if
+// they are not
+func (c *Client) cacheTTLFor(path string) time.Duration {
+	if c.cache == nil {
+		return 0
+	}
+	return c.cacheTTL[resourceType(path)]
+}
+
+// cachedResponse returns a cached response to a GET request
+func (c *Client) cachedResponse(key string) (*Response, bool) {
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	value := &Response{}
+	if err := json.Unmarshal(data, value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// cacheResponse stores the response to a GET request
+func (c *Client) cacheResponse(key string, value *Response, ttl time.Duration) {
+	data, err := json.Marshal(&Response{Data: value.Data, NextPage: value.NextPage})
+	if err != nil {
+		return
+	}
+	c.cache.Set(key, data, ttl)
+}
+
+// memberLists are the lists of each type of resource which change when a
+// user is added or removed
+var memberLists = map[string][]string{
+	"workspaces": {"users", "workspace_memberships"},
+	"teams":      {"users", "team_memberships"},
+}
+
+// invalidate removes the cached responses for the resource at path and the
+// paths below it
+func (c *Client) invalidate(path string) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.DeletePrefix(path + "?")
+	c.cache.DeletePrefix(path + "/")
+
+	for _, action := range []string{"/addUser", "/removeUser"} {
+		parent, ok := strings.CutSuffix(path, action)
+		if !ok {
+			continue
+		}
+		for _, list := range memberLists[resourceType(parent)] {
+			c.invalidate(parent + "/" + list)
+		}
+
+		// The users of a workspace are listed at /users?workspace={gid}
+		if resourceType(parent) == "workspaces" {
+			c.cache.DeletePrefix("/users?")
+		}
+	}
+}
+
+// LRUCache is an in-memory Cache which holds a limited number of values,
+// discarding the least recently used when it is full
+type LRUCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache creates a cache holding up to size values
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get implements Cache
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		l.remove(element)
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set implements Cache
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &lruEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if element, ok := l.entries[key]; ok {
+		element.Value = entry
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(entry)
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+// DeletePrefix implements Cache
+func (l *LRUCache) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.entries {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+}
+
+// Len returns the number of values in the cache, including any which have
+// expired but not yet been removed
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,168 @@
+package asana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Cache(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		w.Write([]byte(`{"data": {"gid": "1", "name": "Urgent"}}`))
+	}))
+	defer server.Close()
+
+	cache := NewLRUCache(10)
+	client, err := NewClient(WithBaseURL(server.URL), WithCache(cache, DefaultCacheTTL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		tag := &Tag{ID: "1"}
+		if err := tag.Fetch(client); err != nil {
+			t.Fatal(err)
+		}
+		if tag.Name != "Urgent" {
+			t.Errorf("Unexpected tag name %q", tag.Name)
+		}
+	}
+	if n := requests["GET /tags/1"]; n != 1 {
+		t.Errorf("Expected one request but saw %d", n)
+	}
+
+	// Requesting other fields is a separate entry
+	if err := (&Tag{ID: "1"}).Fetch(client, Fields(Tag{})); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests["GET /tags/1"]; n != 2 {
+		t.Errorf("Expected a second request for other fields but saw %d", n)
+	}
+
+	// Updating the tag invalidates it
+	if err := (&Tag{ID: "1"}).Update(client, &UpdateTagRequest{Name: String("Urgent")}); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected the cache to be empty but saw %d entries", cache.Len())
+	}
+	if err := (&Tag{ID: "1"}).Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests["GET /tags/1"]; n != 3 {
+		t.Errorf("Expected a request after invalidation but saw %d", n)
+	}
+
+	// Tasks are not cached
+	for i := 0; i < 2; i++ {
+		if err := (&Task{ID: "1"}).Fetch(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := requests["GET /tasks/1"]; n != 2 {
+		t.Errorf("Expected tasks to be fetched every time but saw %d", n)
+	}
+}
+
+func TestClient_Cache_MemberLists(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"data": [{"gid": "5"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"gid": "5"}}`))
+	}))
+	defer server.Close()
+
+	ttl := DefaultCacheTTL()
+	ttl["team_memberships"] = time.Hour
+	client, err := NewClient(WithBaseURL(server.URL), WithCache(NewLRUCache(10), ttl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	team := &Team{ID: "1"}
+	workspace := &Workspace{ID: "2"}
+	list := func() {
+		if _, _, err := team.Users(client); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := team.Memberships(client); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := workspace.Users(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list()
+	list()
+	if requests["GET /teams/1/users"] != 1 || requests["GET /teams/1/team_memberships"] != 1 || requests["GET /users"] != 1 {
+		t.Errorf("Expected the lists to be cached but saw %v", requests)
+	}
+
+	if _, err := team.AddUser(client, "5"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workspace.AddUser(client, "5"); err != nil {
+		t.Fatal(err)
+	}
+	list()
+	if requests["GET /teams/1/users"] != 2 || requests["GET /teams/1/team_memberships"] != 2 || requests["GET /users"] != 2 {
+		t.Errorf("Expected adding users to invalidate the lists but saw %v", requests)
+	}
+
+	if err := team.RemoveUser(client, "5"); err != nil {
+		t.Fatal(err)
+	}
+	list()
+	if requests["GET /teams/1/users"] != 3 || requests["GET /users"] != 2 {
+		t.Errorf("Expected removing a team member to invalidate only the team's lists but saw %v", requests)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("/a?", []byte("a"), time.Minute)
+	cache.Set("/b?", []byte("b"), time.Minute)
+	cache.Get("/a?")
+	cache.Set("/c?", []byte("c"), time.Minute)
+
+	if _, ok := cache.Get("/b?"); ok {
+		t.Error("Expected the least recently used value to be evicted")
+	}
+	if value, ok := cache.Get("/a?"); !ok || string(value) != "a" {
+		t.Errorf("Expected a but saw %q", value)
+	}
+
+	cache.Set("/d?", []byte("d"), -time.Second)
+	if _, ok := cache.Get("/d?"); ok {
+		t.Error("Expected an expired value to be missing")
+	}
+
+	cache.Set("/a/b?", []byte("ab"), time.Minute)
+	cache.DeletePrefix("/a")
+	if cache.Len() != 0 {
+		t.Errorf("Expected the cache to be empty but saw %d entries", cache.Len())
+	}
+}
+
+func TestResourceType(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/users/me":                         "users",
+		"/users/123":                        "users",
+		"/workspaces/1/users":               "users",
+		"/workspaces/1":                     "workspaces",
+		"/custom_fields/5":                  "custom_fields",
+		"/projects/1/custom_field_settings": "custom_field_settings",
+	} {
+		if actual := resourceType(path); actual != expected {
+			t.Errorf("Expected %s to be %s but saw %s", path, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package asana
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// Doer sends HTTP requests. It is satisfied by *http.Client.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// ClientOption configures a Client when it is created
+type ClientOption func(*Client) error
+
+// WithHTTPClient sends requests with doer, which must authenticate them,
+// instead of a client built from the other options
+func WithHTTPClient(doer Doer) ClientOption {
+	return func(c *Client) error {
+		if doer == nil {
+			return errors.New("HTTP client must not be nil")
+		}
+		c.HTTPClient = doer
+		return nil
+	}
+}
+
+// WithBaseURL sends requests to baseURL instead of the default BaseURL
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return errors.Wrap(err, "Invalid base URL")
+		}
+		c.BaseURL = u
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithTimeout limits the time taken by each call, including any retries and
+// reading the response. The default is 10 seconds. Attachment uploads are
+// limited by WithUploadTimeout instead.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return errors.New("Timeout must be positive")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithUploadTimeout limits the time taken by each attachment upload. Large
+// files can take minutes to send, so by default uploads have no deadline
+// other than that of the context passed to CreateAttachmentContext. The
+// timeout applies in addition to the context.
+func WithUploadTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return errors.New("Upload timeout must be positive")
+		}
+		c.uploadTimeout = timeout
+		return nil
+	}
+}
+
+// WithTransport sends requests through transport instead of
+// http.DefaultTransport. It cannot be combined with WithHTTPClient.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) error {
+		if transport == nil {
+			return errors.New("Transport must not be nil")
+		}
+		c.transport = transport
+		return nil
+	}
+}
+
+// withTokenSource authenticates requests with tokens from source
+func withTokenSource(source oauth2.TokenSource) ClientOption {
+	return func(c *Client) error {
+		c.tokenSource = source
+		return nil
+	}
+}
+
+// WithFeatures enables API features, such as StringIDs, for every request
+func WithFeatures(features ...Feature) ClientOption {
+	return func(c *Client) error {
+		c.DefaultOptions.Enable = append(c.DefaultOptions.Enable, features...)
+		return nil
+	}
+}
+
+// WithoutFeatures disables API features for every request
+func WithoutFeatures(features ...Feature) ClientOption {
+	return func(c *Client) error {
+		c.DefaultOptions.Disable = append(c.DefaultOptions.Disable, features...)
+		return nil
+	}
+}
+
+// WithDefaultFields requests these fields in every request which does not
+// list its own
+func WithDefaultFields(fields ...string) ClientOption {
+	return func(c *Client) error {
+		c.DefaultOptions.Fields = append(c.DefaultOptions.Fields, fields...)
+		return nil
+	}
+}
+
+// WithDebug logs each request and response in full, and asks the API for
+// pretty printed responses
+func WithDebug(debug bool) ClientOption {
+	return func(c *Client) error {
+		if debug {
+			c.DefaultOptions.Debug = Bool(true)
+			c.DefaultOptions.Pretty = Bool(true)
+		}
+		return nil
+	}
+}
+
+// WithVerbosity sets how much is logged: 0 logs only warnings, 1 logs
+// changes made at slog.LevelInfo, 2 also logs data read and every request at
+// slog.LevelDebug, and 3 logs everything down to LevelTrace
+func WithVerbosity(level int) ClientOption {
+	return func(c *Client) error {
+		if level < 0 {
+			return errors.New("Verbosity must not be negative")
+		}
+		c.Verbose = make([]bool, level)
+		return nil
+	}
+}
+
+// RetryPolicy controls how failed requests are retried. Rate limited
+// requests are retried after the delay requested by the API. Server errors
+// and network failures are retried with exponential backoff, but only for
+// GET requests, which are safe to repeat.
+type RetryPolicy struct {
+	// The maximum number of attempts, including the first. Values below 2
+	// disable retries.
+	MaxAttempts int
+
+	// The delay before the first retry, doubled for each further retry up
+	// to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy retries failed requests according to policy. Requests are
+// not retried by default.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if policy.MinBackoff < 0 || policy.MaxBackoff < policy.MinBackoff {
+			return errors.New("Invalid retry backoff")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithRateLimiter waits for limiter before each request
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *Client) error {
+		c.rateLimiter = limiter
+		return nil
+	}
+}
+
+// WithLogger sends log records to logger instead of standard error. Records
+// below the level set by WithVerbosity are not passed to the logger.
+// Credentials in logged headers and bodies are redacted.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) error {
+		if logger == nil {
+			return errors.New("Logger must not be nil")
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithRequestHook calls hook with each HTTP request before it is sent,
+// including retries. The hook may add headers.
+func WithRequestHook(hook func(*http.Request)) ClientOption {
+	return func(c *Client) error {
+		c.requestHooks = append(c.requestHooks, hook)
+		return nil
+	}
+}
+
+// WithResponseHook calls hook with each HTTP response before its body is
+// read, including responses which will be retried
+func WithResponseHook(hook func(*http.Response)) ClientOption {
+	return func(c *Client) error {
+		c.responseHooks = append(c.responseHooks, hook)
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package asana
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Validation(t *testing.T) {
+	if _, err := NewClient(WithBaseURL("/relative")); err == nil {
+		t.Error("Expected a relative base URL to be rejected")
+	}
+	if _, err := NewClient(WithHTTPClient(http.DefaultClient), WithTransport(http.DefaultTransport)); err == nil {
+		t.Error("Expected WithHTTPClient and WithTransport to conflict")
+	}
+	if _, err := NewClient(WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second})); err == nil {
+		t.Error("Expected MaxBackoff below MinBackoff to be rejected")
+	}
+
+	client, err := NewClient(WithFeatures(StringIDs), WithDefaultFields("name"), WithDebug(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.DefaultOptions.Enable) != 1 || client.DefaultOptions.Fields[0] != "name" || !IsTrue(client.DefaultOptions.Pretty) {
+		t.Errorf("Unexpected default options %+v", client.DefaultOptions)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		switch len(requests) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errors": [{"message": "Rate limited"}]}`))
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"data": {"gid": "1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	var hooked int
+	client, err := NewClient(
+		WithBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		WithRequestHook(func(*http.Request) { hooked++ }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&User{ID: "me"}).Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || hooked != 3 {
+		t.Errorf("Expected 3 attempts but saw %d requests and %d hooks", len(requests), hooked)
+	}
+
+	// Server errors are not retried for requests which change data
+	requests = requests[:1]
+	if err := (&Tag{ID: "1"}).Delete(client); err == nil {
+		t.Error("Expected the server error to be returned")
+	}
+	if len(requests) != 2 {
+		t.Errorf("Expected the DELETE not to be retried but saw %v", requests)
+	}
+}
+
+// closeTracker records whether a response body was closed
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestClient_Retry_BodyError(t *testing.T) {
+	body := &closeTracker{Reader: strings.NewReader(`{"errors": [{"message": "Rate limited"}]}`)}
+	client, err := NewClient(
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"0"}},
+				Body:       body,
+			}, nil
+		})),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, "https://app.asana.com/api/1.0/tags", strings.NewReader("{}"))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return nil, errors.New("gone")
+	}
+
+	if _, err := client.send(request, &RequestInfo{}); err == nil {
+		t.Error("Expected the body error to be returned")
+	}
+	if !body.closed {
+		t.Error("Expected the rate limited response to be closed")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/timwehrle/asana-api"
+)
+
+// storedTokenKey is the key of the CLI's token in its token store
+const storedTokenKey = "default"
+
+var errNotLoggedIn = errors.New("not logged in: run 'asana login' or provide --token")
+
+type loginCommand struct {
+	Timeout time.Duration `long:"timeout" default:"5m" description:"How long to wait for authorization in the browser"`
+}
+
+// Execute runs the loopback OAuth flow: it serves the redirect URL on a
+// random local port, sends the user to Asana to authorize the app, and
+// saves the resulting token for later commands.
+func (c *loginCommand) Execute(args []string) error {
+	if options.ClientID == "" {
+		return fmt.Errorf("the --client-id option or ASANA_CLIENT_ID is required to log in")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	redirectURL := fmt.Sprintf("http://%s/callback", listener.Addr())
+	app := newApp(redirectURL)
+
+	request, err := app.NewAuthRequest()
+	if err != nil {
+		return err
+	}
+
+	type callback struct {
+		state, code, err string
+	}
+	callbacks := make(chan callback, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		result := callback{state: q.Get("state"), code: q.Get("code"), err: q.Get("error")}
+		select {
+		case callbacks <- result:
+		default:
+			http.Error(w, "Authorization has already completed", http.StatusConflict)
+			return
+		}
+		if result.err != "" || asana.ValidateState(request.State, result.state) != nil {
+			http.Error(w, "Authorization failed. Return to the terminal for details.", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, "Authorization complete. You can close this window and return to the terminal.")
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Opening %s\n", request.URL)
+	fmt.Println("If your browser does not open, visit the URL above to authorize access.")
+	if err := openBrowser(request.URL); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to open a browser: %v\n", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	var result callback
+	select {
+	case result = <-callbacks:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for authorization")
+	}
+	if result.err != "" {
+		return fmt.Errorf("authorization failed: %s", result.err)
+	}
+
+	token, err := app.Complete(ctx, request, result.state, result.code)
+	if err != nil {
+		return err
+	}
+
+	store, err := tokenStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Save(storedTokenKey, token); err != nil {
+		return err
+	}
+
+	// Later commands need the app's credentials to refresh the token
+	if err := saveAppCredentials(&appCredentials{ClientID: options.ClientID, ClientSecret: options.ClientSecret}); err != nil {
+		return err
+	}
+
+	fmt.Println("Logged in")
+	return nil
+}
+
+func newApp(redirectURL string) *asana.App {
+	return asana.NewApp(&asana.AppConfig{
+		ClientID:     options.ClientID,
+		ClientSecret: options.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"default"},
+		PKCE:         true,
+	})
+}
+
+// configDir returns the directory in the user's config directory where
+// login saves its token and app credentials
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "asana"), nil
+}
+
+// tokenStore returns the store where login saves its token
+func tokenStore() (*asana.FileTokenStore, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return asana.NewFileTokenStore(dir)
+}
+
+// appCredentials identify the OAuth app which obtained the stored token,
+// and which must also be used to refresh it
+type appCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+func appCredentialsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "app.json"), nil
+}
+
+// saveAppCredentials saves the app credentials next to the token, readable
+// only by the current user
+func saveAppCredentials(credentials *appCredentials) error {
+	path, err := appCredentialsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// loadAppCredentials returns the credentials saved by login, or nil if
+// there are none
+func loadAppCredentials() (*appCredentials, error) {
+	path, err := appCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	credentials := &appCredentials{}
+	if err := json.Unmarshal(data, credentials); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", path, err)
+	}
+	return credentials, nil
+}
+
+// storedClient creates a client from the token saved by login, which is
+// refreshed automatically when it expires. The token is refreshed with the
+// app credentials saved by login unless --client-id is given.
+func storedClient(opts ...asana.ClientOption) (*asana.Client, error) {
+	store, err := tokenStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.Load(storedTokenKey); err == asana.ErrTokenNotFound {
+		return nil, errNotLoggedIn
+	}
+
+	if options.ClientID == "" {
+		credentials, err := loadAppCredentials()
+		if err != nil {
+			return nil, err
+		}
+		if credentials == nil || credentials.ClientID == "" {
+			return nil, fmt.Errorf("the app which logged in is unknown: run 'asana login' again, or provide --client-id")
+		}
+		options.ClientID = credentials.ClientID
+		if options.ClientSecret == "" {
+			options.ClientSecret = credentials.ClientSecret
+		}
+	}
+
+	// The redirect URL is not used when refreshing
+	app := newApp("")
+	client, err := app.NewStoredClient(context.Background(), store, storedTokenKey, opts...)
+	if err == asana.ErrTokenNotFound {
+		return nil, errNotLoggedIn
+	}
+	return client, err
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
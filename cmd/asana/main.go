@@ -5,25 +5,22 @@ import (
 	"fmt"
 	"github.com/jessevdk/go-flags"
 	"log"
-	"mime"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/timwehrle/asana-api"
 )
 
-var (
-	True = true
-)
-
 var options struct {
-	Token string `long:"token" description:"Personal Access Token used to authorize access to the API" env:"ASANA_TOKEN" required:"true"`
+	Token string `long:"token" description:"Personal Access Token used to authorize access to the API; defaults to the token saved by login" env:"ASANA_TOKEN"`
 
-	Workspace []string `long:"workspace" short:"w" description:"Workspace to access"`
-	Project   []string `long:"project" short:"p" description:"Project to access"`
-	Task      []string `long:"task" short:"t" description:"Task to access"`
+	ClientID     string `long:"client-id" description:"OAuth client ID of the app used by login" env:"ASANA_CLIENT_ID"`
+	ClientSecret string `long:"client-secret" description:"OAuth client secret of the app used by login" env:"ASANA_CLIENT_SECRET"`
+
+	Workspace []string `long:"workspace" short:"w" description:"Workspace to access, by ID or name"`
+	Project   []string `long:"project" short:"p" description:"Project to access, by ID or name"`
+	Task      []string `long:"task" short:"t" description:"Task to access, by ID or name"`
 
 	Attach     string `long:"attach" description:"Attach a file to a task"`
 	AddSection string `long:"add-section" description:"Add a new section to a project"`
@@ -35,11 +32,6 @@ var options struct {
 	Verbose []bool `short:"v" long:"verbose" description:"Show verbose output"`
 }
 
-func authenticate(req *http.Request) (*url.URL, error) {
-	req.Header.Add("Authorization", "Bearer "+options.Token)
-	return nil, nil
-}
-
 func check(err error) {
 	if err != nil {
 		log.Fatal(err)
@@ -47,22 +39,37 @@ func check(err error) {
 }
 
 func main() {
-	if _, err := flags.Parse(&options); err != nil {
+	parser := flags.NewParser(&options, flags.Default)
+	parser.SubcommandsOptional = true
+	_, err := parser.AddCommand("login", "Authorize access in the browser",
+		"Log in to Asana with OAuth and save the token for later commands", &loginCommand{})
+	check(err)
+
+	if _, err := parser.Parse(); err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			return
+		}
+		os.Exit(1)
+	}
+	if parser.Active != nil {
 		return
 	}
 
 	// Create a client
-	client := asana.NewClient(&http.Client{
-		Transport: &http.Transport{
-			Proxy: authenticate,
-		},
-	})
-	if options.Debug {
-		client.DefaultOptions.Debug = &True
-		client.DefaultOptions.Pretty = &True
+	clientOptions := []asana.ClientOption{
+		asana.WithUserAgent("asana-cli"),
+		asana.WithDebug(options.Debug),
+		asana.WithVerbosity(len(options.Verbose)),
+		asana.WithFeatures(asana.StringIDs, asana.NewSections, asana.NewTaskSubtypes, asana.ProjectPrivacySetting),
+		asana.WithRetryPolicy(asana.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}),
 	}
-	client.Verbose = options.Verbose
-	client.DefaultOptions.Enable = []asana.Feature{asana.StringIDs, asana.NewSections, asana.NewTaskSubtypes, asana.ProjectPrivacySetting}
+	var client *asana.Client
+	if options.Token != "" {
+		client, err = asana.NewClientWithAccessToken(options.Token, clientOptions...)
+	} else {
+		client, err = storedClient(clientOptions...)
+	}
+	check(err)
 
 	// Load a task object
 	if options.Task == nil {
@@ -77,14 +84,16 @@ func main() {
 			}
 
 			for _, w := range options.Workspace {
-				workspace := &asana.Workspace{ID: w}
+				workspace, err := resolveWorkspace(client, w)
+				check(err)
 				check(ListProjects(client, workspace))
 			}
 			return
 		}
 
 		for _, p := range options.Project {
-			project := &asana.Project{ID: p}
+			project, err := resolveProject(client, p)
+			check(err)
 
 			if options.AddSection != "" {
 				request := &asana.SectionBase{
@@ -102,7 +111,8 @@ func main() {
 	}
 
 	for _, t := range options.Task {
-		task := &asana.Task{ID: t}
+		task, err := resolveTask(client, t)
+		check(err)
 		check(task.Fetch(client))
 
 		fmt.Printf("Task %s: %q\n", task.ID, task.Name)
@@ -171,9 +181,8 @@ func addAttachment(task *asana.Task, client *asana.Client) {
 	check(err)
 	defer f.Close()
 	a, err := task.CreateAttachment(client, &asana.NewAttachment{
-		Reader:      f,
-		FileName:    f.Name(),
-		ContentType: mime.TypeByExtension(filepath.Ext(f.Name())),
+		Reader:   f,
+		FileName: filepath.Base(f.Name()),
 	})
 	check(err)
 	fmt.Printf("Attachment added: %+v", a)
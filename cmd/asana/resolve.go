@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timwehrle/asana-api"
+)
+
+// isGID reports whether s looks like an object ID rather than a name
+func isGID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveWorkspace finds a workspace by ID or name
+func resolveWorkspace(client *asana.Client, s string) (*asana.Workspace, error) {
+	if isGID(s) {
+		return &asana.Workspace{ID: s}, nil
+	}
+
+	workspaces, err := client.AllWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	for _, workspace := range workspaces {
+		if strings.EqualFold(workspace.Name, s) {
+			return workspace, nil
+		}
+	}
+	return nil, fmt.Errorf("no workspace named %q", s)
+}
+
+// searchWorkspace returns the workspace in which to look up projects and
+// tasks by name: the first one given with --workspace, or the user's only
+// workspace
+func searchWorkspace(client *asana.Client) (*asana.Workspace, error) {
+	if len(options.Workspace) > 0 {
+		return resolveWorkspace(client, options.Workspace[0])
+	}
+
+	workspaces, err := client.AllWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) != 1 {
+		return nil, fmt.Errorf("use --workspace to choose where to look up names")
+	}
+	return workspaces[0], nil
+}
+
+// lookup finds an object by its exact name with typeahead search. It fails
+// rather than guess, since the object may be changed or deleted.
+func lookup(client *asana.Client, resourceType asana.TypeaheadType, name string) (*asana.TypeaheadResult, error) {
+	workspace, err := searchWorkspace(client)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := workspace.Typeahead(client, resourceType, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	result, err := asana.ExactMatch(results, name)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s in %q: %v", resourceType, workspace.Name, err)
+	}
+	return result, nil
+}
+
+// resolveProject finds a project by ID or name
+func resolveProject(client *asana.Client, s string) (*asana.Project, error) {
+	if isGID(s) {
+		return &asana.Project{ID: s}, nil
+	}
+	result, err := lookup(client, asana.TypeaheadProject, s)
+	if err != nil {
+		return nil, err
+	}
+	return result.Project(), nil
+}
+
+// resolveTask finds a task by ID or name
+func resolveTask(client *asana.Client, s string) (*asana.Task, error) {
+	if isGID(s) {
+		return &asana.Task{ID: s}, nil
+	}
+	result, err := lookup(client, asana.TypeaheadTask, s)
+	if err != nil {
+		return nil, err
+	}
+	return result.Task(), nil
+}
@@ -101,8 +101,13 @@ func IsRateLimited(err error) bool {
 	return false
 }
 
-// IsPayloadTooLarge returns true if the error was a rate limit error
+// IsPayloadTooLarge returns true if the error was a 413 Payload Too Large
+// response, or an upload which was refused because it exceeds
+// MaxAttachmentSize
 func IsPayloadTooLarge(err error) bool {
+	if errors.Is(err, ErrAttachmentTooLarge) {
+		return true
+	}
 	if e, ok := IsAsanaError(err); ok {
 		return e.StatusCode == http.StatusRequestEntityTooLarge
 	}
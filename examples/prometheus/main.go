@@ -0,0 +1,225 @@
+// Command prometheus shows how to export the client's instrumentation in
+// the Prometheus text format without depending on a Prometheus library. It
+// lists the tasks of a project every minute and serves the metrics at
+// /metrics.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/timwehrle/asana-api"
+)
+
+type requestKey struct {
+	method   string
+	endpoint string
+	status   int
+}
+
+type requestStats struct {
+	count         int
+	seconds       float64
+	retries       int
+	requestBytes  int64
+	responseBytes int64
+}
+
+type pageStats struct {
+	pages int
+	items int
+}
+
+// metrics implements asana.Instrumentation, accumulating counters which are
+// rendered when scraped
+type metrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]*requestStats
+	inFlight int
+	waits    map[string]float64
+	pages    map[string]*pageStats
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: map[requestKey]*requestStats{},
+		waits:    map[string]float64{},
+		pages:    map[string]*pageStats{},
+	}
+}
+
+func (m *metrics) RequestStart(ctx context.Context, info *asana.RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+}
+
+func (m *metrics) RequestEnd(ctx context.Context, info *asana.RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+
+	key := requestKey{method: info.Method, endpoint: info.Endpoint, status: info.Status}
+	stats := m.requests[key]
+	if stats == nil {
+		stats = &requestStats{}
+		m.requests[key] = stats
+	}
+	stats.count++
+	stats.seconds += info.Latency.Seconds()
+	stats.retries += info.Retries
+	stats.requestBytes += info.RequestBytes
+	stats.responseBytes += info.ResponseBytes
+}
+
+func (m *metrics) RateLimitWait(ctx context.Context, endpoint string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waits[endpoint] += wait.Seconds()
+}
+
+func (m *metrics) Page(ctx context.Context, endpoint string, items int, more bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.pages[endpoint]
+	if stats == nil {
+		stats = &pageStats{}
+		m.pages[endpoint] = stats
+	}
+	stats.pages++
+	stats.items += items
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+	labels := func(key requestKey) string {
+		return fmt.Sprintf(`{method=%q,endpoint=%q,status=%q}`, key.method, key.endpoint, strconv.Itoa(key.status))
+	}
+
+	series := []struct {
+		name, help, kind string
+		value            func(*requestStats) string
+	}{
+		{"asana_requests_total", "API requests made.", "counter",
+			func(s *requestStats) string { return strconv.Itoa(s.count) }},
+		{"asana_request_duration_seconds_sum", "Total time spent on API requests.", "counter",
+			func(s *requestStats) string { return strconv.FormatFloat(s.seconds, 'g', -1, 64) }},
+		{"asana_request_retries_total", "API request retries.", "counter",
+			func(s *requestStats) string { return strconv.Itoa(s.retries) }},
+		{"asana_request_bytes_total", "API request body bytes sent.", "counter",
+			func(s *requestStats) string { return strconv.FormatInt(s.requestBytes, 10) }},
+		{"asana_response_bytes_total", "API response body bytes received.", "counter",
+			func(s *requestStats) string { return strconv.FormatInt(s.responseBytes, 10) }},
+	}
+	for _, s := range series {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, s.kind)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s%s %s\n", s.name, labels(key), s.value(m.requests[key]))
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP asana_requests_in_flight API requests in progress.\n# TYPE asana_requests_in_flight gauge\n")
+	fmt.Fprintf(w, "asana_requests_in_flight %d\n", m.inFlight)
+
+	fmt.Fprintf(w, "# HELP asana_rate_limit_wait_seconds_total Time requests were delayed by rate limits.\n")
+	fmt.Fprintf(w, "# TYPE asana_rate_limit_wait_seconds_total counter\n")
+	for _, endpoint := range sortedKeys(m.waits) {
+		fmt.Fprintf(w, "asana_rate_limit_wait_seconds_total{endpoint=%q} %s\n", endpoint,
+			strconv.FormatFloat(m.waits[endpoint], 'g', -1, 64))
+	}
+
+	fmt.Fprintf(w, "# HELP asana_pages_total Pages of paginated lists read.\n# TYPE asana_pages_total counter\n")
+	for _, endpoint := range sortedKeys(m.pages) {
+		fmt.Fprintf(w, "asana_pages_total{endpoint=%q} %d\n", endpoint, m.pages[endpoint].pages)
+	}
+	fmt.Fprintf(w, "# HELP asana_page_items_total Items read from paginated lists.\n# TYPE asana_page_items_total counter\n")
+	for _, endpoint := range sortedKeys(m.pages) {
+		fmt.Fprintf(w, "asana_page_items_total{endpoint=%q} %d\n", endpoint, m.pages[endpoint].items)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// countTasks reads every page of a project's tasks
+func countTasks(client *asana.Client, project string) (int, error) {
+	n := 0
+	options := &asana.Options{Limit: 100}
+	for {
+		tasks, nextPage, err := (&asana.Project{ID: project}).Tasks(client, options)
+		if err != nil {
+			return n, err
+		}
+		n += len(tasks)
+		if nextPage == nil {
+			return n, nil
+		}
+		options = &asana.Options{Limit: 100, Offset: nextPage.Offset}
+	}
+}
+
+func main() {
+	token, project := os.Getenv("ASANA_TOKEN"), os.Getenv("ASANA_PROJECT")
+	if token == "" || project == "" {
+		log.Fatal("Set ASANA_TOKEN and ASANA_PROJECT")
+	}
+
+	m := newMetrics()
+	client, err := asana.NewClientWithAccessToken(token,
+		asana.WithInstrumentation(m),
+		asana.WithRetryPolicy(asana.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		for {
+			if n, err := countTasks(client, project); err != nil {
+				log.Print(err)
+			} else {
+				log.Printf("Project has %d tasks", n)
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	http.Handle("/metrics", m)
+	addr := ":9090"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + strings.TrimPrefix(port, ":")
+	}
+	log.Printf("Serving metrics on %s/metrics", addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
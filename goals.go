@@ -0,0 +1,32 @@
+package asana
+
+import "fmt"
+
+// Goal is an objective tracked in a workspace
+type Goal struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// The name of the goal.
+	Name string `json:"name,omitempty"`
+
+	// Free-form textual information associated with the goal.
+	Notes string `json:"notes,omitempty"`
+
+	// The localized day on which this goal is due.
+	DueOn *Date `json:"due_on,omitempty"`
+
+	// The day on which work for this goal begins.
+	StartOn *Date `json:"start_on,omitempty"`
+
+	// The user who owns the goal.
+	Owner *User `json:"owner,omitempty"`
+}
+
+// Fetch loads the full details for this Goal
+func (g *Goal) Fetch(client *Client, options ...*Options) error {
+	client.trace("Loading goal details for %q", g.Name)
+
+	_, err := client.get(fmt.Sprintf("/goals/%s", g.ID), nil, g, options...)
+	return err
+}
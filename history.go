@@ -0,0 +1,587 @@
+package asana
+
+import (
+	"sort"
+	"time"
+)
+
+// TaskChange is a single change to a task, decoded from one of its stories
+type TaskChange struct {
+	// The time at which the change was made
+	At time.Time
+
+	// The user who made the change, if known
+	By *User
+
+	// The typed change, such as *AssignedStory or *SectionChangedStory
+	Event StoryEvent
+}
+
+// TaskState is the state of a task at a point in time, as rebuilt by
+// TaskHistory.StateAt
+type TaskState struct {
+	Name      string
+	Assignee  *User
+	Completed bool
+	Dates     Dates
+
+	// Sections maps project IDs to the section the task was in within that
+	// project. Section changes which do not name their project are recorded
+	// under the empty string.
+	Sections map[string]*Section
+
+	// Projects maps the IDs of the projects the task belonged to onto the
+	// project records.
+	Projects map[string]*Project
+
+	// CustomFields maps custom field IDs onto their value, which has the
+	// same type as the Old and New values of the matching story event.
+	CustomFields map[string]any
+}
+
+// SectionPeriod records how long a task spent in a section
+type SectionPeriod struct {
+	// The project containing the section, or the empty string if unknown
+	Project string
+
+	Section *Section
+	Entered time.Time
+
+	// Left is nil if the task is still in the section
+	Left *time.Time
+}
+
+// AssigneePeriod records how long a task was assigned to a user. Assignee
+// is nil for periods in which the task was unassigned.
+type AssigneePeriod struct {
+	Assignee *User
+	From     time.Time
+
+	// To is nil if the task is still assigned to Assignee
+	To *time.Time
+}
+
+// TaskHistory replays the stories of a task so that its state can be
+// rebuilt at any point in time
+type TaskHistory struct {
+	Task *Task
+
+	// Changes lists the changes to the task in the order they were made.
+	// Comments, likes and other stories which do not change the task are
+	// left out.
+	Changes []*TaskChange
+
+	initial *TaskState
+}
+
+// historyFields returns the story fields needed to rebuild a task's
+// history. Compact sections do not include their project, which is needed
+// to tell apart section changes in different projects.
+func historyFields() *Options {
+	fields := Fields(Story{})
+	fields.Fields = append(fields.Fields,
+		"created_by.name", "assignee.name",
+		"old_section.name", "old_section.project", "new_section.name", "new_section.project")
+	return fields
+}
+
+// hasFields reports whether any of opts lists fields to return
+func hasFields(opts []*Options) bool {
+	for _, o := range opts {
+		if o != nil && len(o.Fields) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// History loads every story for this task and builds its TaskHistory. The
+// task should have been fetched first, so that its current state is known.
+// Unless opts list their own fields, every story field is requested,
+// including the projects of changed sections.
+func (t *Task) History(client *Client, opts ...*Options) (*TaskHistory, error) {
+	client.trace("Loading history for %q", t.Name)
+
+	var fields []*Options
+	if !hasFields(opts) {
+		fields = []*Options{historyFields()}
+	}
+
+	var stories []*Story
+	nextPage := &NextPage{}
+
+	for nextPage != nil {
+		page := &Options{
+			Limit:  100,
+			Offset: nextPage.Offset,
+		}
+
+		allOptions := append(append([]*Options{page}, fields...), opts...)
+		result, next, err := t.Stories(client, allOptions...)
+		if err != nil {
+			return nil, err
+		}
+		nextPage = next
+
+		stories = append(stories, result...)
+	}
+
+	return NewTaskHistory(t, stories), nil
+}
+
+// NewTaskHistory builds the history of a task from its stories, which may be
+// given in any order. The current state of the task is used for any field
+// which the stories never change.
+func NewTaskHistory(task *Task, stories []*Story) *TaskHistory {
+	h := &TaskHistory{Task: task}
+
+	for _, story := range stories {
+		if story.CreatedAt == nil {
+			continue
+		}
+
+		event := story.AsEvent()
+		if !changesTask(event) {
+			continue
+		}
+		if e, ok := event.(*SectionChangedStory); ok {
+			e.OldSection, e.NewSection = withSectionProject(task, e.OldSection, e.NewSection)
+		}
+
+		h.Changes = append(h.Changes, &TaskChange{
+			At:    *story.CreatedAt,
+			By:    story.CreatedBy,
+			Event: event,
+		})
+	}
+
+	sort.SliceStable(h.Changes, func(i, j int) bool {
+		return h.Changes[i].At.Before(h.Changes[j].At)
+	})
+
+	h.initial = h.initialState()
+	return h
+}
+
+func changesTask(event StoryEvent) bool {
+	switch event.(type) {
+	case *AssignedStory, *UnassignedStory, *NameChangedStory, *CompletionChangedStory,
+		*DueDateChangedStory, *StartDateChangedStory, *SectionChangedStory, *ProjectChangedStory,
+		*TextCustomFieldChangedStory, *NumberCustomFieldChangedStory,
+		*EnumCustomFieldChangedStory, *MultiEnumCustomFieldChangedStory,
+		*DateCustomFieldChangedStory, *PeopleCustomFieldChangedStory:
+		return true
+	}
+	return false
+}
+
+// Start returns the time from which the history is known: the creation
+// time of the task, or failing that the time of the first change
+func (h *TaskHistory) Start() time.Time {
+	if h.Task.CreatedAt != nil {
+		return *h.Task.CreatedAt
+	}
+	if len(h.Changes) > 0 {
+		return h.Changes[0].At
+	}
+	return time.Time{}
+}
+
+// initialState works out the state of the task before the first change.
+// Each field takes the old value recorded by the first story which changed
+// it, or the current value of the task if no story changed it. Assigned
+// stories do not record the previous assignee, so the task is taken to have
+// been unassigned before its first assignment.
+func (h *TaskHistory) initialState() *TaskState {
+	state := h.currentState()
+
+	seen := map[string]bool{}
+	first := func(key string) bool {
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+
+	for _, change := range h.Changes {
+		switch e := change.Event.(type) {
+		case *AssignedStory, *UnassignedStory:
+			if first("assignee") {
+				state.Assignee = nil
+			}
+		case *NameChangedStory:
+			if first("name") {
+				state.Name = e.OldName
+			}
+		case *CompletionChangedStory:
+			if first("completed") {
+				state.Completed = !e.Completed
+			}
+		case *DueDateChangedStory, *StartDateChangedStory:
+			if first("dates") {
+				state.Dates = Dates{}
+				if old, _ := changedDates(e); old != nil {
+					state.Dates = *old
+				}
+			}
+		case *SectionChangedStory:
+			project := sectionProject(e.NewSection, e.OldSection)
+			if first("section:" + project) {
+				if e.OldSection != nil {
+					state.Sections[project] = e.OldSection
+				} else {
+					delete(state.Sections, project)
+				}
+			}
+		case *ProjectChangedStory:
+			if e.Project != nil && first("project:"+e.Project.ID) {
+				if e.Added {
+					delete(state.Projects, e.Project.ID)
+				} else {
+					state.Projects[e.Project.ID] = e.Project
+				}
+			}
+		default:
+			if id, old, _, ok := customFieldChange(change.Event); ok && first("field:"+id) {
+				if old == nil {
+					delete(state.CustomFields, id)
+				} else {
+					state.CustomFields[id] = old
+				}
+			}
+		}
+	}
+
+	return state
+}
+
+// currentState returns the state recorded on the task itself
+func (h *TaskHistory) currentState() *TaskState {
+	t := h.Task
+	state := &TaskState{
+		Name:         t.Name,
+		Assignee:     t.Assignee,
+		Completed:    IsTrue(t.Completed),
+		Dates:        Dates{DueOn: t.DueOn, DueAt: t.DueAt, StartOn: t.StartOn},
+		Sections:     map[string]*Section{},
+		Projects:     map[string]*Project{},
+		CustomFields: map[string]any{},
+	}
+
+	for _, p := range t.Projects {
+		state.Projects[p.ID] = p
+	}
+	for _, m := range t.Memberships {
+		if m.Section == nil {
+			continue
+		}
+		project := ""
+		if m.Project != nil {
+			project = m.Project.ID
+			state.Projects[project] = m.Project
+		}
+		state.Sections[project] = m.Section
+	}
+	for _, f := range t.CustomFields {
+		if v := customFieldValue(f); v != nil {
+			state.CustomFields[f.ID] = v
+		}
+	}
+
+	return state
+}
+
+// StateAt rebuilds the state of the task as it was at the given time
+func (h *TaskHistory) StateAt(at time.Time) *TaskState {
+	state := h.initial.clone()
+
+	for _, change := range h.Changes {
+		if change.At.After(at) {
+			break
+		}
+		state.apply(change.Event)
+	}
+
+	return state
+}
+
+func (s *TaskState) clone() *TaskState {
+	c := *s
+	c.Sections = make(map[string]*Section, len(s.Sections))
+	for k, v := range s.Sections {
+		c.Sections[k] = v
+	}
+	c.Projects = make(map[string]*Project, len(s.Projects))
+	for k, v := range s.Projects {
+		c.Projects[k] = v
+	}
+	c.CustomFields = make(map[string]any, len(s.CustomFields))
+	for k, v := range s.CustomFields {
+		c.CustomFields[k] = v
+	}
+	return &c
+}
+
+func (s *TaskState) apply(event StoryEvent) {
+	switch e := event.(type) {
+	case *AssignedStory:
+		s.Assignee = e.Assignee
+	case *UnassignedStory:
+		s.Assignee = nil
+	case *NameChangedStory:
+		s.Name = e.NewName
+	case *CompletionChangedStory:
+		s.Completed = e.Completed
+	case *DueDateChangedStory, *StartDateChangedStory:
+		s.Dates = Dates{}
+		if _, dates := changedDates(e); dates != nil {
+			s.Dates = *dates
+		}
+	case *SectionChangedStory:
+		project := sectionProject(e.NewSection, e.OldSection)
+		if e.NewSection != nil {
+			s.Sections[project] = e.NewSection
+		} else {
+			delete(s.Sections, project)
+		}
+	case *ProjectChangedStory:
+		if e.Project == nil {
+			return
+		}
+		if e.Added {
+			s.Projects[e.Project.ID] = e.Project
+		} else {
+			delete(s.Projects, e.Project.ID)
+			delete(s.Sections, e.Project.ID)
+		}
+	default:
+		if id, _, value, ok := customFieldChange(event); ok {
+			if value == nil {
+				delete(s.CustomFields, id)
+			} else {
+				s.CustomFields[id] = value
+			}
+		}
+	}
+}
+
+// Timeline returns the changes made between from and to, inclusive
+func (h *TaskHistory) Timeline(from, to time.Time) []*TaskChange {
+	var result []*TaskChange
+	for _, change := range h.Changes {
+		if change.At.Before(from) || change.At.After(to) {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// SectionPeriods lists the sections the task has been in, with the times it
+// entered and left each of them, in the order they were entered
+func (h *TaskHistory) SectionPeriods() []*SectionPeriod {
+	var result []*SectionPeriod
+	open := map[string]*SectionPeriod{}
+
+	enter := func(project string, section *Section, at time.Time) {
+		if current := open[project]; current != nil {
+			left := at
+			current.Left = &left
+			delete(open, project)
+		}
+		if section != nil {
+			period := &SectionPeriod{Project: project, Section: section, Entered: at}
+			open[project] = period
+			result = append(result, period)
+		}
+	}
+
+	start := h.Start()
+	projects := make([]string, 0, len(h.initial.Sections))
+	for project := range h.initial.Sections {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	for _, project := range projects {
+		enter(project, h.initial.Sections[project], start)
+	}
+
+	for _, change := range h.Changes {
+		switch e := change.Event.(type) {
+		case *SectionChangedStory:
+			enter(sectionProject(e.NewSection, e.OldSection), e.NewSection, change.At)
+		case *ProjectChangedStory:
+			if e.Project != nil && !e.Added {
+				enter(e.Project.ID, nil, change.At)
+			}
+		}
+	}
+
+	return result
+}
+
+// AssigneePeriods lists who the task has been assigned to over time
+func (h *TaskHistory) AssigneePeriods() []*AssigneePeriod {
+	current := &AssigneePeriod{Assignee: h.initial.Assignee, From: h.Start()}
+	result := []*AssigneePeriod{current}
+
+	for _, change := range h.Changes {
+		var assignee *User
+		switch e := change.Event.(type) {
+		case *AssignedStory:
+			assignee = e.Assignee
+		case *UnassignedStory:
+		default:
+			continue
+		}
+
+		to := change.At
+		current.To = &to
+		current = &AssigneePeriod{Assignee: assignee, From: change.At}
+		result = append(result, current)
+	}
+
+	// Drop an empty leading period when the task was assigned on creation
+	if len(result) > 1 && result[0].Assignee == nil && !result[1].From.After(result[0].From) {
+		result = result[1:]
+	}
+
+	return result
+}
+
+// withSectionProject fills in the project of sections from a section change
+// which, like the compact sections returned by default, do not name it. The
+// project is taken from the task's membership in either section, or from its
+// only membership. The sections are copied rather than modified.
+func withSectionProject(task *Task, old, new *Section) (*Section, *Section) {
+	if sectionProject(old, new) != "" {
+		return old, new
+	}
+
+	var project *Project
+	for _, m := range task.Memberships {
+		if m.Project != nil && m.Section != nil && (sameSection(m.Section, old) || sameSection(m.Section, new)) {
+			project = m.Project
+			break
+		}
+	}
+	if project == nil && len(task.Memberships) == 1 {
+		project = task.Memberships[0].Project
+	}
+	if project == nil {
+		return old, new
+	}
+
+	withProject := func(s *Section) *Section {
+		if s == nil {
+			return nil
+		}
+		c := *s
+		c.Project = project
+		return &c
+	}
+	return withProject(old), withProject(new)
+}
+
+func sameSection(a, b *Section) bool {
+	return a != nil && b != nil && a.ID == b.ID
+}
+
+func sectionProject(sections ...*Section) string {
+	for _, s := range sections {
+		if s != nil && s.Project != nil {
+			return s.Project.ID
+		}
+	}
+	return ""
+}
+
+// changedDates returns the dates before and after a due or start date
+// change
+func changedDates(event StoryEvent) (old, dates *Dates) {
+	switch e := event.(type) {
+	case *DueDateChangedStory:
+		return e.OldDates, e.NewDates
+	case *StartDateChangedStory:
+		return e.OldDates, e.NewDates
+	}
+	return nil, nil
+}
+
+// customFieldChange extracts the field ID and old and new values from a
+// custom field story event. Empty values are returned as nil.
+func customFieldChange(event StoryEvent) (id string, old, value any, ok bool) {
+	var field *CustomField
+	switch e := event.(type) {
+	case *TextCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValue), nilIfEmpty(e.NewValue)
+	case *NumberCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValue), nilIfEmpty(e.NewValue)
+	case *EnumCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValue), nilIfEmpty(e.NewValue)
+	case *MultiEnumCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValues), nilIfEmpty(e.NewValues)
+	case *DateCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValue), nilIfEmpty(e.NewValue)
+	case *PeopleCustomFieldChangedStory:
+		field, old, value = e.CustomField, nilIfEmpty(e.OldValue), nilIfEmpty(e.NewValue)
+	default:
+		return "", nil, nil, false
+	}
+	if field == nil {
+		return "", nil, nil, false
+	}
+	return field.ID, old, value, true
+}
+
+// customFieldValue returns the value of a custom field on a task, typed to
+// match customFieldChange
+func customFieldValue(f *CustomFieldValue) any {
+	switch f.ResourceSubtype {
+	case FieldTypeText:
+		return nilIfEmpty(f.TextValue)
+	case FieldTypeNumber:
+		return nilIfEmpty(f.NumberValue)
+	case FieldTypeEnum:
+		return nilIfEmpty(f.EnumValue)
+	case FieldTypeMultiEnum:
+		return nilIfEmpty(f.MultiEnumValues)
+	case FieldTypeDate:
+		return nilIfEmpty(f.DateValue)
+	case FieldTypePeople:
+		return nilIfEmpty(f.PeopleValue)
+	}
+	return nil
+}
+
+// nilIfEmpty converts typed nil pointers and empty slices to an untyped nil
+func nilIfEmpty[T any](v T) any {
+	switch x := any(v).(type) {
+	case *string:
+		if x == nil {
+			return nil
+		}
+	case *float64:
+		if x == nil {
+			return nil
+		}
+	case *EnumValue:
+		if x == nil {
+			return nil
+		}
+	case *DateValue:
+		if x == nil {
+			return nil
+		}
+	case []*EnumValue:
+		if len(x) == 0 {
+			return nil
+		}
+	case []*User:
+		if len(x) == 0 {
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,143 @@
+package asana
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+)
+
+func TestTaskHistory(t *testing.T) {
+	task := &Task{}
+	if err := json.Unmarshal([]byte(`
+{
+	"gid": "1",
+	"name": "Ship it",
+	"created_at": "2024-01-01T09:00:00Z",
+	"assignee": {"gid": "u2", "name": "Bea"},
+	"memberships": [{"project": {"gid": "p1"}, "section": {"gid": "s3", "name": "Done"}}]
+}
+`), task); err != nil {
+		t.Fatal(err)
+	}
+
+	var stories []*Story
+	if err := json.Unmarshal([]byte(`
+[
+	{"resource_subtype": "section_changed", "created_at": "2024-01-03T09:00:00Z",
+	 "old_section": {"gid": "s2", "name": "Doing", "project": {"gid": "p1"}},
+	 "new_section": {"gid": "s3", "name": "Done", "project": {"gid": "p1"}}},
+	{"resource_subtype": "comment_added", "created_at": "2024-01-02T10:00:00Z", "text": "started"},
+	{"resource_subtype": "section_changed", "created_at": "2024-01-02T09:00:00Z",
+	 "old_section": {"gid": "s1", "name": "To do", "project": {"gid": "p1"}},
+	 "new_section": {"gid": "s2", "name": "Doing", "project": {"gid": "p1"}}},
+	{"resource_subtype": "name_changed", "created_at": "2024-01-02T12:00:00Z",
+	 "old_name": "Ship", "new_name": "Ship it"},
+	{"resource_subtype": "assigned", "created_at": "2024-01-01T09:00:00Z", "assignee": {"gid": "u1", "name": "Al"}},
+	{"resource_subtype": "assigned", "created_at": "2024-01-02T11:00:00Z", "assignee": {"gid": "u2", "name": "Bea"}}
+]
+`), &stories); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewTaskHistory(task, stories)
+
+	if len(h.Changes) != 5 {
+		t.Errorf("Expected 5 changes but saw %d", len(h.Changes))
+	}
+
+	state := h.StateAt(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	if state.Name != "Ship" {
+		t.Errorf("Expected name %q but saw %q", "Ship", state.Name)
+	}
+	if state.Sections["p1"] == nil || state.Sections["p1"].ID != "s2" {
+		t.Errorf("Expected section s2 but saw %+v", state.Sections["p1"])
+	}
+	if state.Assignee == nil || state.Assignee.ID != "u1" {
+		t.Errorf("Expected assignee u1 but saw %+v", state.Assignee)
+	}
+
+	periods := h.SectionPeriods()
+	if len(periods) != 3 {
+		t.Fatalf("Expected 3 section periods but saw %d", len(periods))
+	}
+	if periods[1].Section.ID != "s2" || periods[1].Left == nil || periods[1].Left.Sub(periods[1].Entered) != 24*time.Hour {
+		t.Errorf("Expected one day in Doing but saw %+v", periods[1])
+	}
+	if periods[2].Left != nil {
+		t.Errorf("Expected the task to still be in Done but saw %+v", periods[2])
+	}
+
+	assignees := h.AssigneePeriods()
+	if len(assignees) != 2 || assignees[0].Assignee.ID != "u1" || assignees[1].Assignee.ID != "u2" {
+		t.Errorf("Unexpected assignee periods %+v", assignees)
+	}
+}
+
+func TestTaskHistory_CompactSections(t *testing.T) {
+	task := &Task{}
+	if err := json.Unmarshal([]byte(`
+{
+	"gid": "1",
+	"created_at": "2024-01-01T09:00:00Z",
+	"memberships": [{"project": {"gid": "p1"}, "section": {"gid": "s2", "name": "Doing"}}]
+}
+`), task); err != nil {
+		t.Fatal(err)
+	}
+
+	// Section changes as the API returns them by default, without projects
+	var stories []*Story
+	if err := json.Unmarshal([]byte(`
+[
+	{"gid": "10", "resource_type": "story", "resource_subtype": "section_changed",
+	 "created_at": "2024-01-02T09:00:00Z", "type": "system",
+	 "text": "moved this Task from \"To do\" to \"Doing\"",
+	 "old_section": {"gid": "s1", "resource_type": "section", "name": "To do"},
+	 "new_section": {"gid": "s2", "resource_type": "section", "name": "Doing"}}
+]
+`), &stories); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewTaskHistory(task, stories)
+
+	if state := h.StateAt(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)); state.Sections["p1"] == nil ||
+		state.Sections["p1"].ID != "s1" || len(state.Sections) != 1 {
+		t.Errorf("Expected only section s1 before the change but saw %+v", state.Sections)
+	}
+
+	periods := h.SectionPeriods()
+	if len(periods) != 2 {
+		t.Fatalf("Expected 2 section periods but saw %d", len(periods))
+	}
+	if periods[0].Project != "p1" || periods[0].Section.ID != "s1" || periods[0].Left == nil {
+		t.Errorf("Expected the task to have left To do but saw %+v", periods[0])
+	}
+	if periods[1].Project != "p1" || periods[1].Section.ID != "s2" || !periods[1].Entered.Equal(*periods[0].Left) {
+		t.Errorf("Expected the task to have entered Doing on the change but saw %+v", periods[1])
+	}
+	if stories[0].OldSection.Project != nil {
+		t.Error("Expected the stories not to be modified")
+	}
+}
+
+func TestTask_HistoryFields(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/tasks/1/stories").
+		MatchParam("opt_fields", `new_section\.project`).
+		Reply(200).
+		JSON(o{"data": []o{}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	if _, err := (&Task{ID: "1"}).History(client); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected the projects of sections to be requested")
+	}
+}
@@ -0,0 +1,13 @@
+package asana
+
+import (
+	"github.com/timwehrle/asana-api/richtext"
+)
+
+// ValidateHTMLText checks that text is acceptable to Asana as rich text. It
+// must be well-formed XML with a single <body> root element, and use only the
+// elements and attributes Asana supports. See the richtext package for
+// building and parsing rich text.
+func ValidateHTMLText(text string) error {
+	return richtext.Validate(text)
+}
@@ -0,0 +1,31 @@
+package asana
+
+import "testing"
+
+func TestValidateHTMLText(t *testing.T) {
+	valid := []string{
+		`<body>Hello <strong>world</strong></body>`,
+		`<body><ul><li>one</li><li><a href="https://example.com">two</a></li></ul></body>`,
+		`<body><a data-asana-gid="12345"/> please review</body>`,
+	}
+	for _, text := range valid {
+		if err := ValidateHTMLText(text); err != nil {
+			t.Errorf("Expected %q to be valid but saw %v", text, err)
+		}
+	}
+
+	invalid := []string{
+		`Hello`,
+		`<p>Hello</p>`,
+		`<body><div>Hello</div></body>`,
+		`<body><strong>Hello</body>`,
+		`<body><a onclick="x()">Hello</a></body>`,
+		`<body><body>nested</body></body>`,
+		`<body>one</body><body>two</body>`,
+	}
+	for _, text := range invalid {
+		if err := ValidateHTMLText(text); err == nil {
+			t.Errorf("Expected %q to be invalid", text)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package asana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RequestInfo describes an API request for instrumentation. The fields
+// after Endpoint are filled in when the request ends.
+type RequestInfo struct {
+	RequestID string
+	Method    string
+
+	// The API path with object IDs replaced by {gid}, such as
+	// /tasks/{gid}/stories, which keeps the number of distinct values low
+	Endpoint string
+
+	// The HTTP status of the final response, or zero if none was received
+	Status int
+
+	// The time from the first attempt until the response was read,
+	// including retries and rate limit waits
+	Latency time.Duration
+
+	// The number of times the request was retried
+	Retries int
+
+	// The size of the request and response bodies
+	RequestBytes  int64
+	ResponseBytes int64
+
+	// The error which failed the request, if any
+	Err error
+}
+
+// Instrumentation receives measurements of the requests made by a Client.
+// Implementations must be safe for concurrent use and should return
+// quickly. Embed NopInstrumentation to implement only some of the methods.
+type Instrumentation interface {
+	// RequestStart is called before a request is first sent
+	RequestStart(ctx context.Context, info *RequestInfo)
+
+	// RequestEnd is called once the response has been read or the request
+	// has failed
+	RequestEnd(ctx context.Context, info *RequestInfo)
+
+	// RateLimitWait is called when a request is delayed, either by the
+	// client's RateLimiter or because the API asked for it to be retried
+	// later
+	RateLimitWait(ctx context.Context, endpoint string, wait time.Duration)
+
+	// Page is called for each page of a paginated list, with the number of
+	// items on the page and whether more pages follow
+	Page(ctx context.Context, endpoint string, items int, more bool)
+}
+
+// NopInstrumentation implements Instrumentation and does nothing
+type NopInstrumentation struct{}
+
+// RequestStart implements Instrumentation
+func (NopInstrumentation) RequestStart(context.Context, *RequestInfo) {}
+
+// RequestEnd implements Instrumentation
+func (NopInstrumentation) RequestEnd(context.Context, *RequestInfo) {}
+
+// RateLimitWait implements Instrumentation
+func (NopInstrumentation) RateLimitWait(context.Context, string, time.Duration) {}
+
+// Page implements Instrumentation
+func (NopInstrumentation) Page(context.Context, string, int, bool) {}
+
+// Span is a timed operation which a tracing library can record
+type Span interface {
+	// SetAttribute annotates the span. Values are strings, ints or bools.
+	SetAttribute(key string, value any)
+
+	// End completes the span, recording the error if the operation failed
+	End(err error)
+}
+
+// Tracer starts spans. Bridge it to a tracing library such as
+// OpenTelemetry to trace API calls.
+type Tracer interface {
+	// StartSpan starts a span as a child of any span in ctx, and returns a
+	// context holding the new span
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// WithInstrumentation reports measurements of every request to
+// instrumentation
+func WithInstrumentation(instrumentation Instrumentation) ClientOption {
+	return func(c *Client) error {
+		if instrumentation == nil {
+			return errors.New("Instrumentation must not be nil")
+		}
+		c.instrumentation = instrumentation
+		return nil
+	}
+}
+
+// WithTracer starts a span for every API call. Spans are named
+// "asana METHOD endpoint" and carry the asana.request_id, http.method,
+// asana.endpoint, http.status_code and asana.retries attributes.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) error {
+		if tracer == nil {
+			return errors.New("Tracer must not be nil")
+		}
+		c.tracer = tracer
+		return nil
+	}
+}
+
+func (c *Client) instrument() Instrumentation {
+	if c.instrumentation != nil {
+		return c.instrumentation
+	}
+	return NopInstrumentation{}
+}
+
+// startSpan starts the span for a call if the client has a tracer
+func (c *Client) startSpan(ctx context.Context, call *Call) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+
+	endpoint := endpointTemplate(call.Path)
+	ctx, span := c.tracer.StartSpan(ctx, "asana "+call.Method+" "+endpoint)
+	span.SetAttribute("asana.request_id", call.RequestID)
+	span.SetAttribute("http.method", call.Method)
+	span.SetAttribute("asana.endpoint", endpoint)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type spanKey struct{}
+
+// annotateSpan adds the results of a request to the call's span
+func annotateSpan(ctx context.Context, info *RequestInfo) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if info.Status != 0 {
+		span.SetAttribute("http.status_code", info.Status)
+	}
+	span.SetAttribute("asana.retries", info.Retries)
+}
+
+// endpointTemplate replaces the object IDs and email addresses in an API
+// path with {gid}
+func endpointTemplate(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isObjectID(segment) {
+			segments[i] = "{gid}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isObjectID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if strings.Contains(segment, "@") || strings.Contains(segment, "%40") {
+		return true
+	}
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// countItems returns the number of items in a list response, or false if
+// the response is not a list
+func countItems(data json.RawMessage) (int, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		return 0, false
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return 0, false
+	}
+	return len(items), true
+}
+
+// countingBody counts the bytes read from a response body
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += int64(n)
+	return n, err
+}
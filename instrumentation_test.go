@@ -0,0 +1,144 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingInstrumentation struct {
+	NopInstrumentation
+
+	mu       sync.Mutex
+	started  []string
+	requests []RequestInfo
+	waits    []time.Duration
+	pages    []int
+}
+
+func (r *recordingInstrumentation) RequestStart(ctx context.Context, info *RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, info.Method+" "+info.Endpoint)
+}
+
+func (r *recordingInstrumentation) RequestEnd(ctx context.Context, info *RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, *info)
+}
+
+func (r *recordingInstrumentation) RateLimitWait(ctx context.Context, endpoint string, wait time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits = append(r.waits, wait)
+}
+
+func (r *recordingInstrumentation) Page(ctx context.Context, endpoint string, items int, more bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages = append(r.pages, items)
+}
+
+type recordingSpan struct {
+	name  string
+	attrs map[string]any
+	ended bool
+	err   error
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *recordingSpan) End(err error)                      { s.ended, s.err = true, err }
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{name: name, attrs: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestClient_Instrumentation(t *testing.T) {
+	throttled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/projects/123/tasks" && !throttled {
+			throttled = true
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		switch r.URL.Path {
+		case "/projects/123/tasks":
+			w.Write([]byte(`{"data": [{"gid": "1"}, {"gid": "2"}], "next_page": {"offset": "abc"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"message": "Not found"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	tracer := &recordingTracer{}
+	client, err := NewClient(WithBaseURL(server.URL), WithInstrumentation(instrumentation), WithTracer(tracer),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := (&Project{ID: "123"}).Tasks(client); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&User{Email: "someone@example.com"}).Fetch(client); err == nil {
+		t.Error("Expected an error")
+	}
+
+	if len(instrumentation.started) != 2 || instrumentation.started[0] != "GET /projects/{gid}/tasks" ||
+		instrumentation.started[1] != "GET /users/{gid}" {
+		t.Errorf("Unexpected requests started %v", instrumentation.started)
+	}
+
+	tasks := instrumentation.requests[0]
+	if tasks.Status != http.StatusOK || tasks.Retries != 1 || tasks.ResponseBytes == 0 || tasks.Err != nil {
+		t.Errorf("Unexpected request info %+v", tasks)
+	}
+	if user := instrumentation.requests[1]; user.Status != http.StatusNotFound || user.Err == nil {
+		t.Errorf("Unexpected request info %+v", user)
+	}
+	if len(instrumentation.waits) != 1 {
+		t.Errorf("Expected one rate limit wait but saw %v", instrumentation.waits)
+	}
+	if len(instrumentation.pages) != 1 || instrumentation.pages[0] != 2 {
+		t.Errorf("Expected one page of two items but saw %v", instrumentation.pages)
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected two spans but saw %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "asana GET /projects/{gid}/tasks" || !span.ended || span.err != nil ||
+		span.attrs["http.status_code"] != http.StatusOK || span.attrs["asana.retries"] != 1 {
+		t.Errorf("Unexpected span %+v", span)
+	}
+	if span := tracer.spans[1]; !span.ended || span.err == nil {
+		t.Errorf("Expected the failed call's span to record its error but saw %+v", span)
+	}
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/tasks/1234/stories":               "/tasks/{gid}/stories",
+		"/users/me":                         "/users/me",
+		"/users/someone%40example.com":      "/users/{gid}",
+		"/workspaces/1/typeahead?type=user": "/workspaces/{gid}/typeahead",
+		"/projects/12/project_memberships":  "/projects/{gid}/project_memberships",
+	} {
+		if actual := endpointTemplate(path); actual != expected {
+			t.Errorf("Expected %s to be %s but saw %s", path, expected, actual)
+		}
+	}
+}
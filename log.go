@@ -1,23 +1,170 @@
 package asana
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
 )
 
-func (c *Client) info(format string, args ...interface{}) {
-	if len(c.Verbose) > 0 {
-		log.Printf(format, args...)
+// LevelTrace is the level of the most detailed log records, which include
+// request and response headers and bodies
+const LevelTrace = slog.LevelDebug - 4
+
+// defaultLogger writes to standard error when the client has no logger
+var defaultLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	Level: LevelTrace,
+	ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.LevelKey && len(groups) == 0 && a.Value.Any() == LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+		return a
+	},
+}))
+
+func (c *Client) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
 	}
+	return defaultLogger
 }
 
-func (c *Client) trace(format string, args ...interface{}) {
-	if len(c.Verbose) > 1 {
-		log.Printf(format, args...)
+// level returns the lowest level the client logs at. Each -v flag in
+// Verbose lowers it by one step from warnings to informational messages,
+// debug messages and finally trace records. Debug logs everything.
+func (c *Client) level() slog.Level {
+	if IsTrue(c.DefaultOptions.Debug) {
+		return LevelTrace
 	}
+	return slog.LevelWarn - slog.Level(4*len(c.Verbose))
+}
+
+func (c *Client) logf(level slog.Level, format string, args ...interface{}) {
+	if level < c.level() {
+		return
+	}
+	ctx := context.Background()
+	logger := c.log()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// info logs changes made through the API
+func (c *Client) info(format string, args ...interface{}) {
+	c.logf(slog.LevelInfo, format, args...)
+}
+
+// trace logs data read through the API
+func (c *Client) trace(format string, args ...interface{}) {
+	c.logf(slog.LevelDebug, format, args...)
 }
 
 func (c *Client) debug(format string, args ...interface{}) {
-	if IsTrue(c.DefaultOptions.Debug) {
-		log.Printf(format, args...)
+	c.logf(LevelTrace, format, args...)
+}
+
+// logRequest records a completed API request
+func (c *Client) logRequest(request *http.Request, requestID xid.ID, resp *http.Response, value *Response, duration time.Duration, err error) {
+	if slog.LevelDebug < c.level() {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID.String()),
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+		slog.Duration("duration", duration),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if value != nil && value.NextPage != nil {
+		attrs = append(attrs, slog.String("next_offset", value.NextPage.Offset))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.log().LogAttrs(request.Context(), slog.LevelDebug, "Asana API request", attrs...)
+}
+
+// dump records the full details of a request or response when debugging.
+// Credentials in headers and bodies are redacted.
+func (c *Client) dump(ctx context.Context, options *Options, requestID xid.ID, msg string, attrs ...slog.Attr) {
+	if !IsTrue(options.Debug) {
+		return
+	}
+	attrs = append([]slog.Attr{slog.String("request_id", requestID.String())}, attrs...)
+	c.log().LogAttrs(ctx, LevelTrace, msg, attrs...)
+}
+
+const redacted = "[REDACTED]"
+
+// maxLoggedBody limits the length of bodies in log records
+const maxLoggedBody = 4096
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"authorization", "cookie", "token", "secret", "password"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// headersAttr returns headers as a log attribute, redacting credentials
+func headersAttr(key string, header http.Header) slog.Attr {
+	attrs := make([]any, 0, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if isSensitive(name) {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group(key, attrs...)
+}
+
+// bodyAttr returns a JSON body as a log attribute, redacting the values of
+// fields which may hold credentials and truncating long bodies
+func bodyAttr(key string, body []byte) slog.Attr {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return slog.String(key, fmt.Sprintf("[%d bytes]", len(body)))
+	}
+
+	redactedBody, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return slog.String(key, fmt.Sprintf("[%d bytes]", len(body)))
+	}
+	if len(redactedBody) > maxLoggedBody {
+		return slog.String(key, string(redactedBody[:maxLoggedBody])+"...")
+	}
+	return slog.String(key, string(redactedBody))
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			if isSensitive(name) {
+				v[name] = redacted
+			} else {
+				v[name] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
 	}
+	return value
 }
@@ -0,0 +1,54 @@
+package asana
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_LogRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		w.Write([]byte(`{"data": {"gid": "1", "access_token": "secret-token", "name": "Alex"}}`))
+	}))
+	defer server.Close()
+
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: LevelTrace}))
+	client, err := NewClient(WithBaseURL(server.URL), WithLogger(logger), WithDebug(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&User{ID: "me"}).Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buffer.String()
+	for _, secret := range []string{"secret-token", "secret-cookie"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %q to be redacted from:\n%s", secret, output)
+		}
+	}
+	for _, expected := range []string{"request_id=", "method=GET", "path=/users/me", "status=200", "Alex"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+}
+
+func TestClient_LogLevel(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: LevelTrace}))
+
+	client, _ := NewClient(WithLogger(logger), WithVerbosity(1))
+	client.info("created")
+	client.trace("listed")
+
+	if !strings.Contains(buffer.String(), "created") || strings.Contains(buffer.String(), "listed") {
+		t.Errorf("Expected only informational messages at verbosity 1 but saw:\n%s", buffer.String())
+	}
+}
@@ -1,5 +1,10 @@
 package asana
 
+import (
+    "fmt"
+    "strings"
+)
+
 type AccessLevel string
 
 const (
@@ -42,25 +47,59 @@ type ProjectMembership struct {
 
 type membershipsRequestParams struct {
     // Globally unique identifier for goal, project, or portfolio
-    Parent string `json:"parent"`
+    Parent string `url:"parent"`
 
     // Optional - Globally unique identifier for team or user.
-    Member string `json:"member,omitempty"`
+    Member string `url:"member,omitempty"`
 }
 
+// Memberships lists the users and teams which are members of this project
 func (p *Project) Memberships(client *Client, options ...*Options) ([]*ProjectMembership, *NextPage, error) {
+    return p.MembershipsOf(client, "", options...)
+}
+
+// MembershipsOf lists the memberships of this project held by the given
+// user or team. All memberships are listed if member is empty.
+func (p *Project) MembershipsOf(client *Client, member string, options ...*Options) ([]*ProjectMembership, *NextPage, error) {
     client.trace("Listing memberships in project %s...\n", p.ID)
     var result []*ProjectMembership
 
     // Make the request
     query := membershipsRequestParams{
         Parent: p.ID,
+        Member: member,
     }
     nextPage, err := client.get("/memberships", query, &result, options...)
     return result, nextPage, err
 }
 
+// AllMemberships repeatedly pages through all memberships of this project
+func (p *Project) AllMemberships(client *Client, options ...*Options) ([]*ProjectMembership, error) {
+    var allMemberships []*ProjectMembership
+    nextPage := &NextPage{}
+
+    var memberships []*ProjectMembership
+    var err error
+
+    for nextPage != nil {
+        page := &Options{
+            Limit:  100,
+            Offset: nextPage.Offset,
+        }
+
+        allOptions := append([]*Options{page}, options...)
+        memberships, nextPage, err = p.Memberships(client, allOptions...)
+        if err != nil {
+            return nil, err
+        }
+
+        allMemberships = append(allMemberships, memberships...)
+    }
+    return allMemberships, nil
+}
+
 type CreateMembershipRequest struct {
+    // The gid of the user or team
     MemberID string
 
     // Whether the member has admin, editor, commenter, or viewer access to the project.
@@ -89,6 +128,212 @@ func (p *Project) CreateMembership(c *Client, req CreateMembershipRequest, optio
     }
     result := &ProjectMembership{}
 
-    err := c.post("/memberships", data, result)
+    err := c.post("/memberships", data, result, options...)
     return result, err
 }
+
+// AddMembers adds each of the given users or teams to this project with the
+// requested access level. Members are added one at a time; if a request
+// fails the memberships created so far are returned along with the error.
+func (p *Project) AddMembers(c *Client, members []CreateMembershipRequest, options ...*Options) ([]*ProjectMembership, error) {
+    result := make([]*ProjectMembership, 0, len(members))
+    for _, member := range members {
+        membership, err := p.CreateMembership(c, member, options...)
+        if err != nil {
+            return result, err
+        }
+        result = append(result, membership)
+    }
+    return result, nil
+}
+
+type updateMembershipRequest struct {
+    AccessLevel AccessLevel `json:"access_level"`
+}
+
+// Update changes the access level of this membership
+func (m *ProjectMembership) Update(client *Client, accessLevel AccessLevel, options ...*Options) error {
+    client.info("Updating membership %s to %s access\n", m.ID, accessLevel)
+
+    return client.put(fmt.Sprintf("/memberships/%s", m.ID), &updateMembershipRequest{AccessLevel: accessLevel}, m, options...)
+}
+
+// Delete removes the member from the project
+func (m *ProjectMembership) Delete(client *Client) error {
+    client.info("Deleting membership %s\n", m.ID)
+
+    return client.delete(fmt.Sprintf("/memberships/%s", m.ID))
+}
+
+// MembershipUpdate is a change of access level for an existing membership
+type MembershipUpdate struct {
+    Membership  *ProjectMembership
+    AccessLevel AccessLevel
+}
+
+// MembershipPlan lists the changes needed to make a project's memberships
+// match a desired list of members
+type MembershipPlan struct {
+    Project *Project
+
+    // Members to add
+    Add []CreateMembershipRequest
+
+    // Existing memberships whose access level must change
+    Update []MembershipUpdate
+
+    // Existing memberships to delete
+    Remove []*ProjectMembership
+
+    // Existing memberships which are not desired but are kept, because
+    // they belong to the project owner or the current user. Removing them
+    // could lock the owner or the caller out of the project; append them
+    // to Remove before applying the plan to delete them anyway.
+    Kept []*ProjectMembership
+}
+
+// Empty reports whether the plan makes no changes
+func (plan *MembershipPlan) Empty() bool {
+    return len(plan.Add) == 0 && len(plan.Update) == 0 && len(plan.Remove) == 0
+}
+
+// String describes the changes, one per line
+func (plan *MembershipPlan) String() string {
+    b := &strings.Builder{}
+    for _, add := range plan.Add {
+        fmt.Fprintf(b, "+ %s", add.MemberID)
+        if add.AccessLevel != nil {
+            fmt.Fprintf(b, " (%s)", *add.AccessLevel)
+        }
+        b.WriteString("\n")
+    }
+    for _, update := range plan.Update {
+        fmt.Fprintf(b, "~ %s (%s -> %s)\n", memberName(update.Membership), update.Membership.AccessLevel, update.AccessLevel)
+    }
+    for _, remove := range plan.Remove {
+        fmt.Fprintf(b, "- %s (%s)\n", memberName(remove), remove.AccessLevel)
+    }
+    return b.String()
+}
+
+func memberName(m *ProjectMembership) string {
+    if m.Member == nil {
+        return m.ID
+    }
+    if m.Member.Name != "" {
+        return fmt.Sprintf("%s %q", m.Member.ID, m.Member.Name)
+    }
+    return m.Member.ID
+}
+
+// PlanMemberships compares the current memberships of this project with the
+// desired list of members and returns the changes needed to reconcile them,
+// without making any. Members whose desired AccessLevel is nil keep their
+// current access level.
+//
+// The memberships of the project owner and the current user are never
+// removed, even if they are not desired; they are listed in the plan's Kept
+// field instead.
+func (p *Project) PlanMemberships(client *Client, desired []CreateMembershipRequest) (*MembershipPlan, error) {
+    current, err := p.AllMemberships(client)
+    if err != nil {
+        return nil, err
+    }
+
+    owner := p.Owner
+    if owner == nil {
+        project := &Project{}
+        if _, err := client.get(fmt.Sprintf("/projects/%s", p.ID), nil, project, &Options{Fields: []string{"owner"}}); err != nil {
+            return nil, err
+        }
+        owner = project.Owner
+    }
+    me, err := client.CurrentUser()
+    if err != nil {
+        return nil, err
+    }
+
+    keep := []string{me.ID}
+    if owner != nil {
+        keep = append(keep, owner.ID)
+    }
+    return planMemberships(p, current, desired, keep...), nil
+}
+
+// planMemberships compares current with desired, never removing the members
+// in keep
+func planMemberships(p *Project, current []*ProjectMembership, desired []CreateMembershipRequest, keep ...string) *MembershipPlan {
+    plan := &MembershipPlan{Project: p}
+
+    kept := make(map[string]bool, len(keep))
+    for _, id := range keep {
+        if id != "" {
+            kept[id] = true
+        }
+    }
+
+    existing := make(map[string]*ProjectMembership, len(current))
+    for _, m := range current {
+        if m.Member != nil {
+            existing[m.Member.ID] = m
+        }
+    }
+
+    wanted := make(map[string]bool, len(desired))
+    for _, d := range desired {
+        if wanted[d.MemberID] {
+            continue
+        }
+        wanted[d.MemberID] = true
+
+        m, ok := existing[d.MemberID]
+        switch {
+        case !ok:
+            plan.Add = append(plan.Add, d)
+        case d.AccessLevel != nil && *d.AccessLevel != m.AccessLevel:
+            plan.Update = append(plan.Update, MembershipUpdate{Membership: m, AccessLevel: *d.AccessLevel})
+        }
+    }
+
+    for _, m := range current {
+        switch {
+        case m.Member != nil && wanted[m.Member.ID]:
+        case m.Member != nil && kept[m.Member.ID]:
+            plan.Kept = append(plan.Kept, m)
+        default:
+            plan.Remove = append(plan.Remove, m)
+        }
+    }
+    return plan
+}
+
+// Apply makes the changes in the plan. Members are added first and removed
+// last, so that a failure part way through does not leave the project with
+// fewer members than intended.
+func (plan *MembershipPlan) Apply(client *Client) error {
+    if _, err := plan.Project.AddMembers(client, plan.Add); err != nil {
+        return err
+    }
+    for _, update := range plan.Update {
+        if err := update.Membership.Update(client, update.AccessLevel); err != nil {
+            return err
+        }
+    }
+    for _, remove := range plan.Remove {
+        if err := remove.Delete(client); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// ReconcileMemberships makes the memberships of this project match the
+// desired list of members, returning the plan that was applied. The project
+// owner and the current user keep their memberships, as in PlanMemberships.
+func (p *Project) ReconcileMemberships(client *Client, desired []CreateMembershipRequest) (*MembershipPlan, error) {
+    plan, err := p.PlanMemberships(client, desired)
+    if err != nil {
+        return nil, err
+    }
+    return plan, plan.Apply(client)
+}
@@ -26,7 +26,7 @@ func TestProject_Memberships(t *testing.T) {
 
 	project := &Project{}
 
-	client := NewClient(http.DefaultClient)
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
 	memberships, _, err := project.Memberships(client)
 	if err != nil {
 		t.Error(err)
@@ -41,3 +41,90 @@ func TestProject_Memberships(t *testing.T) {
 		t.Errorf("Expected membership ID 12345 but saw %s", m.ID)
 	}
 }
+
+func TestPlanMemberships(t *testing.T) {
+	editor := AccessLevelEditor
+	viewer := AccessLevelViewer
+
+	current := []*ProjectMembership{
+		{ID: "1", Member: &ProjectMember{ID: "alice"}, AccessLevel: AccessLevelEditor},
+		{ID: "2", Member: &ProjectMember{ID: "bob"}, AccessLevel: AccessLevelEditor},
+		{ID: "3", Member: &ProjectMember{ID: "carol"}, AccessLevel: AccessLevelAdmin},
+		{ID: "4", Member: &ProjectMember{ID: "team"}, AccessLevel: AccessLevelCommenter},
+	}
+	desired := []CreateMembershipRequest{
+		{MemberID: "alice", AccessLevel: &editor},
+		{MemberID: "bob", AccessLevel: &viewer},
+		{MemberID: "team"},
+		{MemberID: "dave", AccessLevel: &viewer},
+	}
+
+	plan := planMemberships(&Project{ID: "p"}, current, desired)
+
+	if len(plan.Add) != 1 || plan.Add[0].MemberID != "dave" {
+		t.Errorf("Expected dave to be added but saw %v", plan.Add)
+	}
+	if len(plan.Update) != 1 || plan.Update[0].Membership.ID != "2" || plan.Update[0].AccessLevel != viewer {
+		t.Errorf("Expected bob to become a viewer but saw %v", plan.Update)
+	}
+	if len(plan.Remove) != 1 || plan.Remove[0].ID != "3" {
+		t.Errorf("Expected carol to be removed but saw %v", plan.Remove)
+	}
+
+	expected := "+ dave (viewer)\n~ bob (editor -> viewer)\n- carol (admin)\n"
+	if plan.String() != expected {
+		t.Errorf("Unexpected plan description:\n%s", plan.String())
+	}
+
+	if planMemberships(&Project{}, current[:1], desired[:1]).Empty() != true {
+		t.Error("Expected an empty plan")
+	}
+
+	// The members to keep are never removed
+	plan = planMemberships(&Project{ID: "p"}, current, desired, "carol")
+	if len(plan.Remove) != 0 {
+		t.Errorf("Expected nobody to be removed but saw %v", plan.Remove)
+	}
+	if len(plan.Kept) != 1 || plan.Kept[0].ID != "3" {
+		t.Errorf("Expected carol to be kept but saw %v", plan.Kept)
+	}
+}
+
+func TestProject_PlanMemberships(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/memberships").
+		MatchParam("parent", "p").
+		Reply(200).
+		JSON(o{"data": []o{
+			{"gid": "1", "member": o{"gid": "owner"}, "access_level": "admin"},
+			{"gid": "2", "member": o{"gid": "me"}, "access_level": "admin"},
+			{"gid": "3", "member": o{"gid": "bob"}, "access_level": "editor"},
+		}})
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/projects/p").
+		MatchParam("opt_fields", "owner").
+		Reply(200).
+		JSON(o{"data": o{"gid": "p", "owner": o{"gid": "owner"}}})
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/users/me").
+		Reply(200).
+		JSON(o{"data": o{"gid": "me"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	plan, err := (&Project{ID: "p"}).PlanMemberships(client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Remove) != 1 || plan.Remove[0].ID != "3" {
+		t.Errorf("Expected only bob to be removed but saw %v", plan.Remove)
+	}
+	if len(plan.Kept) != 2 {
+		t.Errorf("Expected the owner and current user to be kept but saw %v", plan.Kept)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected the owner and current user to be looked up")
+	}
+}
@@ -0,0 +1,81 @@
+package asana
+
+import (
+	"fmt"
+
+	"github.com/timwehrle/asana-api/richtext"
+)
+
+// appURL is the base of links to objects in the Asana web application
+const appURL = "https://app.asana.com/0"
+
+// Mention returns a rich text node which @-mentions this user
+func (u *User) Mention() *richtext.Node {
+	return richtext.Mention(u.ID)
+}
+
+// Permalink returns the URL of this user's profile in Asana
+func (u *User) Permalink() string {
+	return fmt.Sprintf("%s/profile/%s", appURL, u.ID)
+}
+
+// Mention returns a rich text node which links to this task
+func (t *Task) Mention() *richtext.Node {
+	return richtext.Mention(t.ID)
+}
+
+// Permalink returns the URL of this task in Asana, using PermalinkURL when
+// it has been loaded
+func (t *Task) Permalink() string {
+	if t.PermalinkURL != "" {
+		return t.PermalinkURL
+	}
+	return fmt.Sprintf("%s/0/%s", appURL, t.ID)
+}
+
+// Mention returns a rich text node which links to this project
+func (p *Project) Mention() *richtext.Node {
+	return richtext.Mention(p.ID)
+}
+
+// Permalink returns the URL of this project in Asana, using PermalinkURL
+// when it has been loaded
+func (p *Project) Permalink() string {
+	if p.PermalinkURL != "" {
+		return p.PermalinkURL
+	}
+	return fmt.Sprintf("%s/%s", appURL, p.ID)
+}
+
+// Mention returns a rich text node which links to this section
+func (s *Section) Mention() *richtext.Node {
+	return richtext.Mention(s.ID)
+}
+
+// Permalink returns the URL of this section in Asana. The section's Project
+// must be loaded for the link to open within its project.
+func (s *Section) Permalink() string {
+	project := "0"
+	if s.Project != nil && s.Project.ID != "" {
+		project = s.Project.ID
+	}
+	return fmt.Sprintf("%s/%s/%s", appURL, project, s.ID)
+}
+
+// Mentions returns the IDs of the users and other objects mentioned in the
+// HTMLText of this story. HTMLText is only returned when requested with the
+// html_text field option. Stories may contain markup which is not valid
+// rich text, so only the mentions are read from it.
+func (s *Story) Mentions() []string {
+	return richtext.FindMentions(s.HTMLText)
+}
+
+// CreateRichComment adds a comment story to a task from a rich text document
+func (t *Task) CreateRichComment(client *Client, doc *richtext.Node) (*Story, error) {
+	return t.CreateComment(client, &StoryBase{HTMLText: doc.HTML()})
+}
+
+// UpdateNotes replaces the notes of this task with a rich text document
+func (t *Task) UpdateNotes(client *Client, doc *richtext.Node) error {
+	return t.Update(client, &UpdateTaskRequest{TaskBase: TaskBase{HTMLNotes: doc.HTML()}})
+}
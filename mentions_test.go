@@ -0,0 +1,35 @@
+package asana
+
+import (
+	"testing"
+
+	"github.com/timwehrle/asana-api/richtext"
+)
+
+func TestStory_Mentions(t *testing.T) {
+	user := &User{ID: "42"}
+	task := &Task{ID: "7"}
+
+	doc := richtext.Body(
+		user.Mention(), richtext.Text(" please look at "), task.Mention(),
+		richtext.Text(" with "), user.Mention(),
+	)
+	story := &Story{StoryBase: StoryBase{HTMLText: doc.HTML()}}
+
+	mentions := story.Mentions()
+	if len(mentions) != 2 || mentions[0] != "42" || mentions[1] != "7" {
+		t.Errorf("Expected mentions [42 7] but saw %v", mentions)
+	}
+
+	// Stories may use markup which is not valid rich text
+	story.HTMLText = `<body><p>Hi <a href="https://app.asana.com/0/profile/42" data-asana-gid="42" data-asana-type="user" class="mention">@Ann</a>&nbsp;see<br>` +
+		`<a href="https://example.com">a link</a> and <a data-asana-gid=7>the task</a></body>`
+	mentions = story.Mentions()
+	if len(mentions) != 2 || mentions[0] != "42" || mentions[1] != "7" {
+		t.Errorf("Expected mentions [42 7] from loose markup but saw %v", mentions)
+	}
+
+	if mentions := (&Story{}).Mentions(); len(mentions) != 0 {
+		t.Errorf("Expected no mentions but saw %v", mentions)
+	}
+}
@@ -0,0 +1,71 @@
+package asana
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Call is a single API call passing through the client's middleware
+type Call struct {
+	// Identifies the call in log records and error messages
+	RequestID string
+
+	// The HTTP method and the API path, without the base URL or query
+	Method string
+	Path   string
+
+	// The options of the call, merged with the client's DefaultOptions
+	Options *Options
+
+	// The request data before it is encoded: the query parameters of a GET
+	// request, the data of a POST or PUT request, or the form fields of a
+	// multipart upload as a map[string]string. Nil if there is none.
+	Data any
+}
+
+// Handler makes an API call. It returns the decoded response, or an error
+// which is an *Error if the API rejected the call.
+type Handler func(ctx context.Context, call *Call) (*Response, error)
+
+// Middleware wraps the handler which makes API calls. It may inspect or
+// change the call before passing it to next, inspect or change the result,
+// or return a result without calling next at all.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to every API call made by the client.
+// Middleware added first is outermost, so sees each call first and its
+// result last.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) error {
+		for _, m := range middleware {
+			if m == nil {
+				return errors.New("Middleware must not be nil")
+			}
+		}
+		c.middleware = append(c.middleware, middleware...)
+		return nil
+	}
+}
+
+// execute passes the call through the middleware to the final handler,
+// which sends it
+func (c *Client) execute(ctx context.Context, call *Call, send Handler) (*Response, error) {
+	handler := send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+
+	ctx, span := c.startSpan(ctx, call)
+	value, err := handler(ctx, call)
+	if err == nil && value == nil {
+		err = errors.Errorf("%s Missing response from middleware", call.RequestID)
+	}
+	if span != nil {
+		span.End(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
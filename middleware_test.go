@@ -0,0 +1,112 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": [{"message": "Forbidden"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"gid": "1", "name": "From server"}}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	var failure *Error
+	audit := func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			calls = append(calls, call.Method+" "+call.Path)
+			value, err := next(ctx, call)
+			if e, ok := IsAsanaError(err); ok {
+				failure = e
+			}
+			return value, err
+		}
+	}
+	fields := func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			call.Options.Fields = []string{"name"}
+			return next(ctx, call)
+		}
+	}
+	stub := func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			if call.Path == "/users/stub" {
+				return &Response{Data: json.RawMessage(`{"gid": "stub", "name": "From middleware"}`)}, nil
+			}
+			return next(ctx, call)
+		}
+	}
+
+	client, err := NewClient(WithBaseURL(server.URL), WithMiddleware(audit, fields, stub))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &User{ID: "stub"}
+	if err := user.Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "From middleware" || query != "" {
+		t.Errorf("Expected the call to be short-circuited but saw %q and query %q", user.Name, query)
+	}
+
+	user = &User{ID: "1"}
+	if err := user.Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "From server" || query != "opt_fields=name" {
+		t.Errorf("Expected changed options to be sent but saw query %q", query)
+	}
+
+	if err := (&Tag{ID: "2"}).Delete(client); err == nil {
+		t.Error("Expected an error")
+	}
+	if failure == nil || failure.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected middleware to see the API error but saw %v", failure)
+	}
+
+	if len(calls) != 3 || calls[2] != "DELETE /tags/2" {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+}
+
+func TestClient_Middleware_MultipartFields(t *testing.T) {
+	var sent bool
+	replace := func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*Response, error) {
+			call.Data = map[string]any{"parent": "123"}
+			return next(ctx, call)
+		}
+	}
+	client, _ := NewClient(
+		WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = true
+			return MockResponse(http.StatusOK, o{"gid": "987"})
+		})),
+		WithMiddleware(replace),
+	)
+
+	_, err := client.CreateAttachment("123", &NewAttachment{
+		Reader:   io.NopCloser(strings.NewReader("some notes")),
+		FileName: "notes.txt",
+	})
+	if err == nil || !strings.Contains(err.Error(), "must be a map[string]string") {
+		t.Errorf("Expected the form fields to be rejected but saw %v", err)
+	}
+	if sent {
+		t.Error("Expected nothing to be uploaded")
+	}
+}
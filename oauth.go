@@ -2,8 +2,15 @@ package asana
 
 import (
 	"context"
-	"time"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
@@ -12,6 +19,13 @@ var defaultOAuthEndpoint = oauth2.Endpoint{
 	TokenURL: "https://app.asana.com/-/oauth_token",
 }
 
+const defaultRevokeURL = "https://app.asana.com/-/oauth_revoke"
+
+// ErrInvalidState is returned when the state passed to the redirect URL does
+// not match the one sent with the authorization request, which may indicate
+// a forged request
+var ErrInvalidState = errors.New("OAuth state does not match the authorization request")
+
 // AppConfig provides the details needed to authenticate users with
 // Asana on behalf of an Asana client application
 type AppConfig struct {
@@ -19,11 +33,22 @@ type AppConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	DisplayUI    bool // Force prompt for user permission when authorizing
+
+	// The OAuth scopes to request, such as "default" or "openid". No scope
+	// parameter is sent when empty, which grants the app's default access.
+	Scopes []string
+
+	// Use PKCE with an S256 challenge in requests created by
+	// NewAuthRequest. Required for apps which cannot keep their client
+	// secret private, such as command line tools.
+	PKCE bool
 }
 
 // App represents an Asana client application
 type App struct {
-	config *oauth2.Config
+	config    *oauth2.Config
+	pkce      bool
+	revokeURL string
 }
 
 // NewApp creates a new App with the provided configuration
@@ -39,34 +64,157 @@ func NewApp(config *AppConfig) *App {
 			ClientSecret: config.ClientSecret,
 			Endpoint:     endpoint,
 			RedirectURL:  config.RedirectURL,
-			Scopes:       nil,
+			Scopes:       config.Scopes,
 		},
+		pkce:      config.PKCE,
+		revokeURL: defaultRevokeURL,
+	}
+}
+
+// NewState generates a random, unguessable value for the state parameter
+// of an authorization request
+func NewState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "Unable to generate OAuth state")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidateState checks the state received by the redirect URL against the
+// expected value, returning ErrInvalidState if they differ
+func ValidateState(expected, received string) error {
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(received)) != 1 {
+		return ErrInvalidState
 	}
+	return nil
+}
+
+// AuthRequest holds the secrets of a single authorization attempt, which
+// must be kept until the user is redirected back to the app
+type AuthRequest struct {
+	// The URL to send the user to
+	URL string
+
+	// The state parameter sent with the request
+	State string
+
+	// The PKCE code verifier, if PKCE is enabled
+	Verifier string
+}
+
+// NewAuthRequest starts an authorization attempt with a new random state
+// and, if the app uses PKCE, a new code verifier
+func (a *App) NewAuthRequest() (*AuthRequest, error) {
+	state, err := NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &AuthRequest{State: state}
+	var opts []oauth2.AuthCodeOption
+	if a.pkce {
+		request.Verifier = oauth2.GenerateVerifier()
+		opts = append(opts, oauth2.S256ChallengeOption(request.Verifier))
+	}
+	request.URL = a.AuthCodeURL(state, opts...)
+	return request, nil
+}
+
+// Complete validates the state received by the redirect URL and exchanges
+// the authorization code for a token
+func (a *App) Complete(ctx context.Context, request *AuthRequest, state, code string) (*oauth2.Token, error) {
+	if err := ValidateState(request.State, state); err != nil {
+		return nil, err
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if request.Verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(request.Verifier))
+	}
+	return a.ExchangeContext(ctx, code, opts...)
 }
 
 // see oauth2 package
-func (a *App) AuthCodeURL(state string) string {
-	return a.config.AuthCodeURL(state)
+func (a *App) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return a.config.AuthCodeURL(state, opts...)
 }
 
 // see oauth2 package
-func (a *App) Exchange(code string) (*oauth2.Token, error) {
-	ctx := context.Background()
-	return a.config.Exchange(ctx, code)
+func (a *App) Exchange(code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return a.ExchangeContext(context.Background(), code, opts...)
+}
+
+// ExchangeContext exchanges an authorization code for a token. Pass
+// oauth2.VerifierOption with the code verifier when using PKCE.
+func (a *App) ExchangeContext(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	token, err := a.config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to exchange authorization code")
+	}
+	return token, nil
 }
 
 // see oauth2 package
 func (a *App) Refresh(token *oauth2.Token) (*oauth2.Token, error) {
-	ctx := context.Background()
-	invalidToken := *token
-	invalidToken.Expiry = time.Time{}
-	ts := a.config.TokenSource(ctx, token)
-	return ts.Token()
+	return a.RefreshContext(context.Background(), token)
+}
+
+// RefreshContext obtains a new access token using the token's refresh
+// token, even if the current access token has not yet expired. Asana does
+// not rotate refresh tokens, so the returned token keeps the original one.
+func (a *App) RefreshContext(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errors.New("Unable to refresh a token without a refresh token")
+	}
+
+	// Without an access token the token source always asks for a new one
+	ts := a.config.TokenSource(ctx, &oauth2.Token{RefreshToken: token.RefreshToken})
+	refreshed, err := ts.Token()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to refresh token")
+	}
+	return refreshed, nil
+}
+
+// Revoke invalidates the token. Revoking the refresh token also revokes
+// all access tokens issued from it, so it is used when present.
+func (a *App) Revoke(ctx context.Context, token *oauth2.Token) error {
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+
+	form := url.Values{
+		"client_id":     {a.config.ClientID},
+		"client_secret": {a.config.ClientSecret},
+		"token":         {value},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "Request error")
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		httpClient = c
+	}
+	resp, err := httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "Unable to revoke token")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("Unable to revoke token: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
 }
 
 // NewClient creates a new Asana client using the provided credentials
-func (a *App) NewClient(token *oauth2.Token) *Client {
+func (a *App) NewClient(token *oauth2.Token, opts ...ClientOption) (*Client, error) {
 	ctx := context.Background()
-	client := a.config.Client(ctx, token)
-	return NewClient(client)
+	return NewClientWithTokenSource(a.config.TokenSource(ctx, token), opts...)
 }
@@ -0,0 +1,67 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestApp_RefreshForcesNewToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			t.Errorf("Unexpected token request %v", r.Form)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	app := NewApp(&AppConfig{ClientID: "id", ClientSecret: "secret"})
+	app.config.Endpoint.TokenURL = server.URL
+
+	// The current token is still valid, but must be replaced anyway
+	token := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	refreshed, err := app.RefreshContext(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.AccessToken != "new" || refreshed.RefreshToken != "refresh" {
+		t.Errorf("Unexpected refreshed token %+v", refreshed)
+	}
+}
+
+func TestApp_AuthRequest(t *testing.T) {
+	app := NewApp(&AppConfig{ClientID: "id", RedirectURL: "http://127.0.0.1/cb", Scopes: []string{"default"}, PKCE: true})
+
+	request, err := app.NewAuthRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(request.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("state") != request.State || q.Get("scope") != "default" {
+		t.Errorf("Unexpected authorization URL %s", request.URL)
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") != oauth2.S256ChallengeFromVerifier(request.Verifier) {
+		t.Errorf("Expected a PKCE challenge in %s", request.URL)
+	}
+
+	if err := ValidateState(request.State, request.State); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateState(request.State, "forged"); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState but saw %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// ClientPoolConfig configures a ClientPool
+type ClientPoolConfig struct {
+	// Store holds the token of each user. Required.
+	Store TokenStore
+
+	// App refreshes OAuth tokens, saving them back to Store. If nil the
+	// stored tokens are used as they are, which suits Personal Access
+	// Tokens and service account tokens.
+	App *App
+
+	// Transport is shared by all clients in the pool. Defaults to a clone
+	// of http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Clients which have not been used for this long are removed from the
+	// pool. Zero keeps clients until they are evicted explicitly.
+	IdleTimeout time.Duration
+
+	// The request budget of each user, and of the pool as a whole. Asana
+	// limits each token to 1500 requests per minute on paid plans.
+	UserLimit   RateLimit
+	GlobalLimit RateLimit
+
+	// Options configure each new client. The pool provides the transport,
+	// so WithTransport and WithHTTPClient cannot be used, and WithCache
+	// cannot be used because a cache must not be shared between users.
+	Options []ClientOption
+
+	// Cache creates the response cache of each new client, which is used as
+	// with WithCache. If nil the clients do not cache responses.
+	Cache func(key string) Cache
+
+	// The lifetimes of cached responses. Defaults to DefaultCacheTTL.
+	CacheTTL map[string]time.Duration
+
+	// Configure is called for each new client before it is first used. A
+	// client created at the same time as another for the same user is
+	// discarded, after Configure has been called for it.
+	Configure func(key string, client *Client)
+}
+
+// ClientPool holds a Client for each of many users, created on first use
+// from the user's stored token. All clients share one transport, so
+// connections are reused across users. It is safe for concurrent use.
+type ClientPool struct {
+	config    ClientPoolConfig
+	transport http.RoundTripper
+	global    RateLimiter
+
+	mu      sync.Mutex
+	clients map[string]*poolEntry
+
+	// Incremented by Evict and Close, so that a client created from a token
+	// loaded before then is not added to the pool
+	evictions uint64
+}
+
+type poolEntry struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// NewClientPool creates an empty pool
+func NewClientPool(config *ClientPoolConfig) (*ClientPool, error) {
+	if config.Store == nil {
+		return nil, errors.New("A client pool requires a token store")
+	}
+
+	// Every client would share a cache given in the options
+	probe := &Client{}
+	for _, opt := range config.Options {
+		if err := opt(probe); err != nil {
+			return nil, err
+		}
+	}
+	if probe.cache != nil {
+		return nil, errors.New("A client pool cannot share a cache between users, use ClientPoolConfig.Cache")
+	}
+
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	return &ClientPool{
+		config:    *config,
+		transport: transport,
+		global:    NewRateLimiter(config.GlobalLimit),
+		clients:   map[string]*poolEntry{},
+	}, nil
+}
+
+// Client returns the client for the user whose token is stored under key,
+// creating it if needed. It returns ErrTokenNotFound if there is no token.
+//
+// The token is loaded without holding the pool's lock, so a slow store does
+// not delay other users. If two calls create a client for the same user at
+// once, both receive the one which was added to the pool first.
+func (p *ClientPool) Client(key string) (*Client, error) {
+	p.mu.Lock()
+	now := time.Now()
+	p.evictIdle(now)
+	if entry, ok := p.clients[key]; ok {
+		entry.lastUsed = now
+		p.mu.Unlock()
+		return entry.client, nil
+	}
+	evictions := p.evictions
+	p.mu.Unlock()
+
+	client, err := p.newClient(key)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now = time.Now()
+	if entry, ok := p.clients[key]; ok {
+		entry.lastUsed = now
+		return entry.client, nil
+	}
+	if p.evictions == evictions {
+		p.clients[key] = &poolEntry{client: client, lastUsed: now}
+	}
+	return client, nil
+}
+
+func (p *ClientPool) newClient(key string) (*Client, error) {
+	transport := &rateLimitedTransport{
+		limiters: []RateLimiter{p.global, NewRateLimiter(p.config.UserLimit)},
+		next:     p.transport,
+	}
+
+	opts := append(p.config.Options[:len(p.config.Options):len(p.config.Options)], WithTransport(transport))
+	if p.config.Cache != nil {
+		ttl := p.config.CacheTTL
+		if ttl == nil {
+			ttl = DefaultCacheTTL()
+		}
+		opts = append(opts, WithCache(p.config.Cache(key), ttl))
+	}
+
+	var client *Client
+	if p.config.App != nil {
+		// The oauth2 package refreshes tokens through the client in the
+		// context
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport})
+
+		var err error
+		client, err = p.config.App.NewStoredClient(ctx, p.config.Store, key, opts...)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		token, err := p.config.Store.Load(key)
+		if err != nil {
+			return nil, err
+		}
+		client, err = NewClientWithAccessToken(token.AccessToken, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.config.Configure != nil {
+		p.config.Configure(key, client)
+	}
+	return client, nil
+}
+
+func (p *ClientPool) evictIdle(now time.Time) {
+	if p.config.IdleTimeout <= 0 {
+		return
+	}
+	for key, entry := range p.clients {
+		if now.Sub(entry.lastUsed) > p.config.IdleTimeout {
+			delete(p.clients, key)
+		}
+	}
+}
+
+// Evict removes the client for key from the pool, for example after the
+// user's token has been revoked
+func (p *ClientPool) Evict(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.clients, key)
+	p.evictions++
+}
+
+// Len returns the number of clients in the pool
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evictIdle(time.Now())
+	return len(p.clients)
+}
+
+// Close removes all clients and closes idle connections of the shared
+// transport
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	p.clients = map[string]*poolEntry{}
+	p.evictions++
+	p.mu.Unlock()
+
+	if t, ok := p.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
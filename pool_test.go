@@ -0,0 +1,199 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestClientPool(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data": {"gid": "1"}}`))
+	}))
+	defer server.Close()
+
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Save("alice", &oauth2.Token{AccessToken: "a"})
+	store.Save("bob", &oauth2.Token{AccessToken: "b"})
+
+	pool, err := NewClientPool(&ClientPoolConfig{
+		Store:       store,
+		IdleTimeout: time.Hour,
+		Options:     []ClientOption{WithBaseURL(server.URL)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, key := range []string{"alice", "bob", "alice"} {
+		client, err := pool.Client(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := (&User{ID: "me"}).Fetch(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(seen) != 3 || seen[0] != "Bearer a" || seen[1] != "Bearer b" || seen[2] != "Bearer a" {
+		t.Errorf("Unexpected authorization headers %v", seen)
+	}
+	if pool.Len() != 2 {
+		t.Errorf("Expected 2 clients but saw %d", pool.Len())
+	}
+
+	first, _ := pool.Client("alice")
+	pool.Evict("alice")
+	second, _ := pool.Client("alice")
+	if first == second {
+		t.Error("Expected a new client after eviction")
+	}
+
+	if _, err := pool.Client("carol"); err != ErrTokenNotFound {
+		t.Errorf("Expected ErrTokenNotFound but saw %v", err)
+	}
+}
+
+func TestClientPool_Cache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"data": {"gid": "1", "name": "` + r.Header.Get("Authorization") + `"}}`))
+	}))
+	defer server.Close()
+
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Save("alice", &oauth2.Token{AccessToken: "a"})
+	store.Save("bob", &oauth2.Token{AccessToken: "b"})
+
+	if _, err := NewClientPool(&ClientPoolConfig{
+		Store:   store,
+		Options: []ClientOption{WithCache(NewLRUCache(10), DefaultCacheTTL())},
+	}); err == nil {
+		t.Error("Expected a shared cache to be rejected")
+	}
+
+	pool, err := NewClientPool(&ClientPoolConfig{
+		Store:   store,
+		Options: []ClientOption{WithBaseURL(server.URL)},
+		Cache:   func(key string) Cache { return NewLRUCache(10) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, key := range []string{"alice", "bob", "alice", "bob"} {
+		client, err := pool.Client(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user := &User{ID: "1"}
+		if err := user.Fetch(client); err != nil {
+			t.Fatal(err)
+		}
+		if expected := "Bearer " + key[:1]; user.Name != expected {
+			t.Errorf("Expected %s to see %q but saw %q", key, expected, user.Name)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected one request for each user but saw %d", requests)
+	}
+}
+
+// blockingStore waits for release before loading the token for "slow"
+type blockingStore struct {
+	loading chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Load(key string) (*oauth2.Token, error) {
+	if key == "slow" {
+		s.loading <- struct{}{}
+		<-s.release
+	}
+	return &oauth2.Token{AccessToken: key}, nil
+}
+
+func (s *blockingStore) Save(key string, token *oauth2.Token) error {
+	return nil
+}
+
+func TestClientPool_SlowStore(t *testing.T) {
+	store := &blockingStore{loading: make(chan struct{}), release: make(chan struct{})}
+	pool, err := NewClientPool(&ClientPoolConfig{Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	slow := make(chan *Client)
+	go func() {
+		client, _ := pool.Client("slow")
+		slow <- client
+	}()
+	<-store.loading
+
+	// Other users are not held up while the slow token loads
+	done := make(chan struct{})
+	go func() {
+		pool.Client("fast")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected another user's client while a token was loading")
+	}
+
+	// A client created while the user was evicted is not kept
+	pool.Evict("slow")
+	close(store.release)
+	client := <-slow
+	if client == nil {
+		t.Fatal("Expected a client")
+	}
+	if pool.Len() != 1 {
+		t.Errorf("Expected only the fast client to be kept but saw %d", pool.Len())
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{Requests: 2, Per: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two requests are allowed immediately, and the rest every 50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected requests to be delayed but took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Expected the wait to be cancelled but saw %v", err)
+	}
+
+	if NewRateLimiter(RateLimit{}) != nil {
+		t.Error("Expected no limiter for an unlimited budget")
+	}
+}
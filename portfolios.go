@@ -3,6 +3,9 @@ package asana
 type Portfolio struct {
 	// Read-only. Globally unique ID of the object
 	ID string `json:"gid,omitempty"`
+
+	// Read-only. The name of the portfolio.
+	Name string `json:"name,omitempty"`
 }
 
 // Portfolios returns a list of portfolios in this workspace
@@ -176,6 +176,9 @@ type Project struct {
 	// share this project with other users in this organization without
 	// explicitly checking to see if they have access.
 	Public *bool `json:"public,omitempty"`
+
+	// Read-only. A URL that points directly to the project within Asana.
+	PermalinkURL string `json:"permalink_url,omitempty"`
 }
 
 func (p *Project) GetID() string {
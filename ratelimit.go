@@ -0,0 +1,102 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter delays requests to stay within a request budget
+type RateLimiter interface {
+	// Wait blocks until a request may be made, or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// RateLimit is a request budget: on average Requests requests may be made
+// every Per, with bursts of up to Requests requests
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// tokenBucket is a RateLimiter which refills a bucket of request tokens at a
+// steady rate
+type tokenBucket struct {
+	interval time.Duration
+	capacity float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter enforcing the limit. It returns nil
+// if the limit allows unlimited requests.
+func NewRateLimiter(limit RateLimit) RateLimiter {
+	if limit.Requests <= 0 || limit.Per <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		interval: limit.Per / time.Duration(limit.Requests),
+		capacity: float64(limit.Requests),
+		tokens:   float64(limit.Requests),
+		last:     time.Now(),
+	}
+}
+
+// Wait implements RateLimiter
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	// Take a token now, waiting for it to be refilled if the bucket is empty
+	b.tokens--
+	delay := time.Duration(-b.tokens * float64(b.interval))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Return the unused token
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// rateLimitedTransport waits for each of its limiters before sending a
+// request
+type rateLimitedTransport struct {
+	limiters []RateLimiter
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	for _, limiter := range t.limiters {
+		if limiter == nil {
+			continue
+		}
+		if err := limiter.Wait(request.Context()); err != nil {
+			if request.Body != nil {
+				request.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(request)
+}
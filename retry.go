@@ -0,0 +1,128 @@
+package asana
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// minReportedWait is the shortest rate limiter wait reported to the
+// client's Instrumentation, so that requests which were not delayed are not
+// reported
+const minReportedWait = time.Millisecond
+
+// send makes an HTTP request, waiting for the rate limiter first and
+// retrying according to the client's retry policy. The number of retries is
+// recorded in info.
+func (c *Client) send(request *http.Request, info *RequestInfo) (*http.Response, error) {
+	ctx := request.Context()
+
+	for attempt := 1; ; attempt++ {
+		info.Retries = attempt - 1
+		if c.rateLimiter != nil {
+			start := time.Now()
+			if err := c.rateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+			if wait := time.Since(start); wait >= minReportedWait {
+				c.instrument().RateLimitWait(ctx, info.Endpoint, wait)
+			}
+		}
+		for _, hook := range c.requestHooks {
+			hook(request)
+		}
+
+		resp, err := c.HTTPClient.Do(request)
+		if err == nil {
+			for _, hook := range c.responseHooks {
+				hook(resp)
+			}
+		}
+
+		delay, retry := c.retryDelay(request, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		// Give up rather than wait past the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		// Discard the failed response, so its connection can be reused
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		// Recreate the body for the next attempt
+		var body io.ReadCloser
+		if request.GetBody != nil {
+			if body, err = request.GetBody(); err != nil {
+				return nil, errors.Wrap(err, "Unable to resend request body")
+			}
+		}
+
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			c.instrument().RateLimitWait(ctx, info.Endpoint, delay)
+		}
+		c.trace("Retrying %s %s in %s (attempt %d)", request.Method, request.URL.Path, delay, attempt+1)
+		if err := sleep(ctx, delay); err != nil {
+			if body != nil {
+				body.Close()
+			}
+			return nil, err
+		}
+
+		request = request.Clone(ctx)
+		request.Body = body
+	}
+}
+
+// retryDelay decides whether a request should be retried, and after how
+// long
+func (c *Client) retryDelay(request *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.retry.MaxAttempts {
+		return 0, false
+	}
+
+	// A request body which cannot be recreated cannot be resent
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return 0, false
+	}
+
+	backoff := c.retry.MinBackoff << (attempt - 1)
+	if backoff > c.retry.MaxBackoff || backoff < 0 {
+		backoff = c.retry.MaxBackoff
+	}
+
+	switch {
+	case err != nil:
+		// The request may have been processed, so only repeat safe requests
+		return backoff, request.Method == http.MethodGet && request.Context().Err() == nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Rate limited requests were not processed and are safe to repeat
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		return backoff, true
+	case resp.StatusCode >= 500:
+		return backoff, request.Method == http.MethodGet
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
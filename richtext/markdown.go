@@ -0,0 +1,538 @@
+package richtext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The Markdown dialect used by ToMarkdown and FromMarkdown follows
+// CommonMark with these additions and differences:
+//
+//   - Newlines are line breaks, as they are in Asana, rather than soft
+//     breaks within a paragraph.
+//   - ~~text~~ is struck through and ++text++ is underlined.
+//   - <asana:GID> is a mention of, or link to, the Asana object GID, and
+//     ![](asana:GID) is an inline image of an attachment.
+//   - Only two levels of heading exist; deeper headings become level two.
+
+// ToMarkdown converts a document to Markdown. A nil document is empty.
+func ToMarkdown(n *Node) string {
+	if n == nil {
+		return ""
+	}
+	w := &markdownWriter{}
+	if n.Kind == KindBody {
+		w.blocks(n.Children, "")
+	} else {
+		w.blocks([]*Node{n}, "")
+	}
+	return w.b.String()
+}
+
+type markdownWriter struct {
+	b strings.Builder
+}
+
+// atLineStart reports whether the output is at the start of a line
+func (w *markdownWriter) atLineStart() bool {
+	s := w.b.String()
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+func (w *markdownWriter) newline() {
+	if !w.atLineStart() {
+		w.b.WriteString("\n")
+	}
+}
+
+// blocks writes a mixture of inline and block nodes, prefixing every line
+// with indent
+func (w *markdownWriter) blocks(nodes []*Node, indent string) {
+	var inline []*Node
+	flush := func() {
+		if len(inline) == 0 {
+			return
+		}
+		w.lines(inlineMarkdown(inline), indent, true)
+		inline = nil
+	}
+
+	for _, n := range nodes {
+		if !n.Kind.isBlock() {
+			inline = append(inline, n)
+			continue
+		}
+
+		flush()
+		w.newline()
+
+		switch n.Kind {
+		case KindHeading1:
+			w.heading("# ", n, indent)
+		case KindHeading2:
+			w.heading("## ", n, indent)
+		case KindBlockquote:
+			w.lines(inlineMarkdown(n.Children), indent+"> ", true)
+			w.newline()
+		case KindPre:
+			w.lines("```\n"+PlainText(n)+"\n```", indent, false)
+			w.newline()
+		case KindOrderedList, KindUnorderedList:
+			w.list(n, indent)
+		}
+	}
+	flush()
+}
+
+func (w *markdownWriter) heading(marker string, n *Node, indent string) {
+	text := strings.ReplaceAll(inlineMarkdown(n.Children), "\n", " ")
+	w.b.WriteString(indent + marker + text + "\n")
+}
+
+func (w *markdownWriter) list(n *Node, indent string) {
+	for i, item := range n.Children {
+		marker := "- "
+		if n.Kind == KindOrderedList {
+			marker = strconv.Itoa(i+1) + ". "
+		}
+
+		w.newline()
+		w.b.WriteString(indent + marker)
+		w.blocks(item.Children, indent+strings.Repeat(" ", len(marker)))
+		w.newline()
+	}
+}
+
+// lines writes text split into lines, prefixing each line after the first
+// written at the current position with indent
+func (w *markdownWriter) lines(text, indent string, escape bool) {
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			w.b.WriteString("\n")
+		}
+		if w.atLineStart() {
+			w.b.WriteString(indent)
+		}
+		if escape {
+			line = escapeLineStart(line)
+		}
+		w.b.WriteString(line)
+	}
+}
+
+var orderedMarker = regexp.MustCompile(`^(\d+)([.)]) `)
+
+// escapeLineStart escapes text at the start of a line which would otherwise
+// be read as a block marker
+func escapeLineStart(line string) string {
+	switch {
+	case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"),
+		strings.HasPrefix(line, "- "), strings.HasPrefix(line, "+ "):
+		return `\` + line
+	case orderedMarker.MatchString(line):
+		return orderedMarker.ReplaceAllString(line, `$1\$2 `)
+	}
+	return line
+}
+
+// escapeText escapes characters in text which would otherwise be read as
+// inline Markdown
+func escapeText(s string) string {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '*', '_', '`', '[', ']', '<':
+			b.WriteByte('\\')
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				b.WriteByte('\\')
+			}
+		case '+', '~':
+			if i+1 < len(s) && s[i+1] == c {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func inlineMarkdown(nodes []*Node) string {
+	b := &strings.Builder{}
+	for _, n := range nodes {
+		writeInlineMarkdown(b, n)
+	}
+	return b.String()
+}
+
+func writeInlineMarkdown(b *strings.Builder, n *Node) {
+	switch n.Kind {
+	case KindText:
+		b.WriteString(escapeText(n.Text))
+	case KindStrong:
+		b.WriteString("**" + inlineMarkdown(n.Children) + "**")
+	case KindEm:
+		b.WriteString("_" + inlineMarkdown(n.Children) + "_")
+	case KindUnderline:
+		b.WriteString("++" + inlineMarkdown(n.Children) + "++")
+	case KindStrike:
+		b.WriteString("~~" + inlineMarkdown(n.Children) + "~~")
+	case KindCode:
+		text := PlainText(n)
+		fence := "`"
+		for strings.Contains(text, fence) {
+			fence += "`"
+		}
+		if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+			text = " " + text + " "
+		}
+		b.WriteString(fence + text + fence)
+	case KindLink:
+		b.WriteString("[" + inlineMarkdown(n.Children) + "](" + escapeURL(n.Href) + ")")
+	case KindMention:
+		b.WriteString("<asana:" + n.GID + ">")
+	case KindImage:
+		b.WriteString("![](asana:" + n.GID + ")")
+	default:
+		// Blocks cannot be nested in inline content, so keep their text
+		b.WriteString(escapeText(PlainText(n)))
+	}
+}
+
+func escapeURL(url string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(url)
+}
+
+// FromMarkdown converts Markdown to a document
+func FromMarkdown(markdown string) *Node {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.TrimSuffix(markdown, "\n")
+	p := &markdownParser{lines: strings.Split(markdown, "\n")}
+	return Body(p.blocks(0)...)
+}
+
+type markdownParser struct {
+	lines []string
+	pos   int
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemPattern = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+)
+
+// blocks parses lines until one is indented less than indent
+func (p *markdownParser) blocks(indent int) []*Node {
+	var result []*Node
+	addText := func(nodes ...*Node) {
+		for _, n := range nodes {
+			last := len(result) - 1
+			if n.Kind == KindText && last >= 0 && result[last].Kind == KindText {
+				result[last].Text += n.Text
+				continue
+			}
+			result = append(result, n)
+		}
+	}
+
+	for p.pos < len(p.lines) {
+		raw := p.lines[p.pos]
+		if endsIndent(raw, indent) {
+			break
+		}
+		line := dedent(raw, indent)
+
+		switch {
+		case strings.HasPrefix(line, "```"):
+			result = append(result, p.fence(indent))
+			continue
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			heading := Heading2(parseInline(m[2])...)
+			if len(m[1]) == 1 {
+				heading = Heading1(parseInline(m[2])...)
+			}
+			result = append(result, heading)
+		case strings.HasPrefix(line, ">"):
+			result = append(result, p.blockquote(indent))
+			continue
+		case isListItem(line):
+			result = append(result, p.list(indent))
+			continue
+		default:
+			addText(parseInline(line)...)
+			if p.pos+1 < len(p.lines) && !p.startsBlock(p.pos+1, indent) {
+				addText(Text("\n"))
+			}
+		}
+		p.pos++
+	}
+
+	return result
+}
+
+// startsBlock reports whether line i begins a block element, or ends the
+// current level of indentation
+func (p *markdownParser) startsBlock(i, indent int) bool {
+	raw := p.lines[i]
+	if endsIndent(raw, indent) {
+		return true
+	}
+	line := dedent(raw, indent)
+	return strings.HasPrefix(line, "```") || headingPattern.MatchString(line) ||
+		strings.HasPrefix(line, ">") || isListItem(line)
+}
+
+// isListItem reports whether line starts a list item at the current
+// indentation
+func isListItem(line string) bool {
+	m := listItemPattern.FindStringSubmatch(line)
+	return m != nil && m[1] == ""
+}
+
+func (p *markdownParser) fence(indent int) *Node {
+	var lines []string
+	for p.pos++; p.pos < len(p.lines); p.pos++ {
+		line := dedent(p.lines[p.pos], indent)
+		if strings.HasPrefix(line, "```") {
+			p.pos++
+			break
+		}
+		lines = append(lines, line)
+	}
+	return Pre(strings.Join(lines, "\n"))
+}
+
+func (p *markdownParser) blockquote(indent int) *Node {
+	var lines []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := dedent(p.lines[p.pos], indent)
+		if !strings.HasPrefix(line, ">") {
+			break
+		}
+		line = strings.TrimPrefix(line, ">")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	return Blockquote(parseInline(strings.Join(lines, "\n"))...)
+}
+
+func (p *markdownParser) list(indent int) *Node {
+	first := listItemPattern.FindStringSubmatch(dedent(p.lines[p.pos], indent))
+	ordered := first[2][0] >= '0' && first[2][0] <= '9'
+
+	var items []*Node
+	for p.pos < len(p.lines) {
+		// Items of an enclosing list are less indented
+		if endsIndent(p.lines[p.pos], indent) {
+			break
+		}
+		line := dedent(p.lines[p.pos], indent)
+		m := listItemPattern.FindStringSubmatch(line)
+		if m == nil || m[1] != "" || (m[2][0] >= '0' && m[2][0] <= '9') != ordered {
+			break
+		}
+
+		// Continuation lines are indented to the start of the item text. An
+		// empty item consumes its line, which would otherwise be left as
+		// whitespace after the list.
+		contentIndent := indent + len(m[2]) + 1
+		if strings.TrimSpace(m[3]) == "" {
+			p.pos++
+		} else {
+			p.lines[p.pos] = strings.Repeat(" ", contentIndent) + m[3]
+		}
+		items = append(items, ListItem(p.blocks(contentIndent)...))
+	}
+
+	if ordered {
+		return OrderedList(items...)
+	}
+	return UnorderedList(items...)
+}
+
+// endsIndent reports whether line ends a block indented by indent. Blank
+// lines end list items.
+func endsIndent(line string, indent int) bool {
+	if strings.TrimSpace(line) == "" {
+		return indent > 0
+	}
+	return indentOf(line) < indent
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+func dedent(line string, indent int) string {
+	for i := 0; i < indent && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+var inlineDelimiters = []struct {
+	marker string
+	build  func(...*Node) *Node
+}{
+	{"**", Strong},
+	{"__", Strong},
+	{"~~", Strike},
+	{"++", Underline},
+	{"*", Em},
+	{"_", Em},
+}
+
+// parseInline parses inline Markdown into text and inline elements
+func parseInline(s string) []*Node {
+	var result []*Node
+	text := &strings.Builder{}
+
+	flush := func() {
+		if text.Len() > 0 {
+			result = append(result, Text(text.String()))
+			text.Reset()
+		}
+	}
+	emit := func(n *Node) {
+		flush()
+		result = append(result, n)
+	}
+
+outer:
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isPunct(rest[1]):
+			text.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			fence := rest[:len(rest)-len(strings.TrimLeft(rest, "`"))]
+			if end := strings.Index(rest[len(fence):], fence); end >= 0 {
+				code := rest[len(fence) : len(fence)+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				emit(Code(code))
+				i += 2*len(fence) + end
+				continue
+			}
+			text.WriteString(fence)
+			i += len(fence)
+			continue
+
+		case strings.HasPrefix(rest, "![](asana:"):
+			if end := strings.IndexByte(rest, ')'); end >= 0 {
+				emit(Image(rest[len("![](asana:"):end]))
+				i += end + 1
+				continue
+			}
+
+		case strings.HasPrefix(rest, "<asana:"):
+			if end := strings.IndexByte(rest, '>'); end >= 0 {
+				emit(Mention(rest[len("<asana:"):end]))
+				i += end + 1
+				continue
+			}
+
+		case rest[0] == '<':
+			if end := strings.IndexByte(rest, '>'); end >= 0 {
+				url := rest[1:end]
+				if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "mailto:") {
+					emit(Link(url, Text(url)))
+					i += end + 1
+					continue
+				}
+			}
+
+		case rest[0] == '[':
+			if label, href, n, ok := parseLink(rest); ok {
+				emit(Link(href, parseInline(label)...))
+				i += n
+				continue
+			}
+		}
+
+		for _, d := range inlineDelimiters {
+			if !strings.HasPrefix(rest, d.marker) {
+				continue
+			}
+			// Underscores inside words, as in snake_case, are not emphasis
+			if d.marker[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+				break
+			}
+			inner := rest[len(d.marker):]
+			end := findClosing(inner, d.marker)
+			if end <= 0 {
+				break
+			}
+			emit(d.build(parseInline(inner[:end])...))
+			i += 2*len(d.marker) + end
+			continue outer
+		}
+
+		text.WriteByte(rest[0])
+		i++
+	}
+
+	flush()
+	return result
+}
+
+// findClosing finds the next unescaped marker in s, or returns -1
+func findClosing(s, marker string) int {
+	for i := 0; i+len(marker) <= len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], marker) {
+			// A single marker must not close on the first of a double one
+			if len(marker) == 1 && strings.HasPrefix(s[i+1:], marker) {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLink parses [label](href) at the start of s, returning the number of
+// bytes consumed
+func parseLink(s string) (label, href string, n int, ok bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if !strings.HasPrefix(s[i+1:], "(") {
+					return "", "", 0, false
+				}
+				end := strings.IndexByte(s[i+2:], ')')
+				if end < 0 {
+					return "", "", 0, false
+				}
+				return s[1:i], s[i+2 : i+2+end], i + 3 + end, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
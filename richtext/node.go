@@ -0,0 +1,360 @@
+// Package richtext builds, parses and converts the rich text used by Asana
+// in fields such as html_notes and html_text.
+//
+// Asana accepts only a strict XML subset of HTML: a single <body> element
+// containing text and the elements <strong>, <em>, <u>, <s>, <code>, <ol>,
+// <ul>, <li>, <a>, <h1>, <h2>, <blockquote>, <pre> and <img>. Links with a
+// data-asana-gid attribute are rendered by Asana as @-mentions of, or links
+// to, the object with that ID. Line breaks are plain newlines in the text.
+package richtext
+
+import (
+	"strings"
+)
+
+// Kind identifies the type of a Node
+type Kind string
+
+// Kinds of Node
+const (
+	KindBody          Kind = "body"
+	KindText          Kind = "text"
+	KindStrong        Kind = "strong"
+	KindEm            Kind = "em"
+	KindUnderline     Kind = "u"
+	KindStrike        Kind = "s"
+	KindCode          Kind = "code"
+	KindOrderedList   Kind = "ol"
+	KindUnorderedList Kind = "ul"
+	KindListItem      Kind = "li"
+	KindLink          Kind = "a"
+	KindMention       Kind = "mention"
+	KindHeading1      Kind = "h1"
+	KindHeading2      Kind = "h2"
+	KindBlockquote    Kind = "blockquote"
+	KindPre           Kind = "pre"
+	KindImage         Kind = "img"
+)
+
+// known reports whether k is one of the kinds above. Nodes of the zero
+// Kind or an unknown kind are replaced by their children when rendered.
+func (k Kind) known() bool {
+	switch k {
+	case KindBody, KindText, KindStrong, KindEm, KindUnderline, KindStrike,
+		KindCode, KindOrderedList, KindUnorderedList, KindListItem, KindLink,
+		KindMention, KindHeading1, KindHeading2, KindBlockquote, KindPre,
+		KindImage:
+		return true
+	}
+	return false
+}
+
+// isBlock reports whether nodes of this kind start on a new line
+func (k Kind) isBlock() bool {
+	switch k {
+	case KindOrderedList, KindUnorderedList, KindHeading1, KindHeading2, KindBlockquote, KindPre:
+		return true
+	}
+	return false
+}
+
+// Node is an element or text in a rich text document
+type Node struct {
+	Kind Kind
+
+	// The content of a text node
+	Text string
+
+	// The target of a link. Mentions parsed from Asana may also carry the
+	// URL of the mentioned object.
+	Href string
+
+	// The ID of the Asana object referred to by a mention or image
+	GID string
+
+	Children []*Node
+}
+
+// Body creates the root node of a document. List items which are not in a
+// list are rendered in a bulleted list.
+func Body(children ...*Node) *Node {
+	return &Node{Kind: KindBody, Children: children}
+}
+
+// Text creates a text node. Newlines in the text are line breaks. Control
+// characters which cannot appear in XML are removed.
+func Text(text string) *Node {
+	return &Node{Kind: KindText, Text: xmlText(text)}
+}
+
+// xmlText removes the characters which are not allowed in XML documents,
+// and replaces invalid UTF-8 with the replacement character
+func xmlText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n', r == '\r':
+			return r
+		case r < 0x20, r >= 0xD800 && r <= 0xDFFF, r == 0xFFFE, r == 0xFFFF:
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(s, "\uFFFD"))
+}
+
+// Strong creates bold text
+func Strong(children ...*Node) *Node {
+	return &Node{Kind: KindStrong, Children: children}
+}
+
+// Em creates italic text
+func Em(children ...*Node) *Node {
+	return &Node{Kind: KindEm, Children: children}
+}
+
+// Underline creates underlined text
+func Underline(children ...*Node) *Node {
+	return &Node{Kind: KindUnderline, Children: children}
+}
+
+// Strike creates struck through text
+func Strike(children ...*Node) *Node {
+	return &Node{Kind: KindStrike, Children: children}
+}
+
+// Code creates inline code
+func Code(text string) *Node {
+	return &Node{Kind: KindCode, Children: []*Node{Text(text)}}
+}
+
+// Link creates a hyperlink to href. Links cannot be nested, so links in
+// children are replaced by their content and mentions by their text.
+func Link(href string, children ...*Node) *Node {
+	return &Node{Kind: KindLink, Href: href, Children: unlink(children)}
+}
+
+// unlink replaces links and mentions in nodes with their content
+func unlink(nodes []*Node) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		switch n.Kind {
+		case KindLink:
+			result = append(result, unlink(n.Children)...)
+		case KindMention:
+			if text := PlainText(n); text != "" {
+				result = append(result, Text(text))
+			}
+		default:
+			if len(n.Children) > 0 {
+				c := *n
+				c.Children = unlink(n.Children)
+				n = &c
+			}
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// Mention creates an @-mention of a user, or a link to a task, project or
+// other object, which Asana renders from the object ID
+func Mention(gid string) *Node {
+	return &Node{Kind: KindMention, GID: gid}
+}
+
+// Image creates an inline image of an attachment which has already been
+// uploaded to the same object
+func Image(attachmentGID string) *Node {
+	return &Node{Kind: KindImage, GID: attachmentGID}
+}
+
+// Heading1 creates a top level heading
+func Heading1(children ...*Node) *Node {
+	return &Node{Kind: KindHeading1, Children: children}
+}
+
+// Heading2 creates a second level heading
+func Heading2(children ...*Node) *Node {
+	return &Node{Kind: KindHeading2, Children: children}
+}
+
+// Blockquote creates a quotation
+func Blockquote(children ...*Node) *Node {
+	return &Node{Kind: KindBlockquote, Children: children}
+}
+
+// Pre creates a preformatted code block
+func Pre(text string) *Node {
+	return &Node{Kind: KindPre, Children: []*Node{Text(text)}}
+}
+
+// OrderedList creates a numbered list. Items which are not list items are
+// wrapped in one.
+func OrderedList(items ...*Node) *Node {
+	return &Node{Kind: KindOrderedList, Children: listItems(items)}
+}
+
+// UnorderedList creates a bulleted list. Items which are not list items are
+// wrapped in one.
+func UnorderedList(items ...*Node) *Node {
+	return &Node{Kind: KindUnorderedList, Children: listItems(items)}
+}
+
+// ListItem creates an item of a list. Items which are not placed in a list
+// are rendered in a bulleted list.
+func ListItem(children ...*Node) *Node {
+	return &Node{Kind: KindListItem, Children: children}
+}
+
+func listItems(items []*Node) []*Node {
+	result := make([]*Node, 0, len(items))
+	for _, item := range items {
+		if item.Kind != KindListItem {
+			item = ListItem(item)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// Walk calls fn for n and each of its descendants in document order. The
+// children of a node are skipped when fn returns false.
+func Walk(n *Node, fn func(*Node) bool) {
+	if n == nil || !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		Walk(child, fn)
+	}
+}
+
+// PlainText returns the text content of n without any markup
+func PlainText(n *Node) string {
+	b := &strings.Builder{}
+	Walk(n, func(n *Node) bool {
+		b.WriteString(n.Text)
+		return true
+	})
+	return b.String()
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// HTML renders the node as markup accepted by Asana. A node other than a
+// body is wrapped in one.
+func (n *Node) HTML() string {
+	b := &strings.Builder{}
+	if n.Kind != KindBody {
+		n = Body(n)
+	}
+	n.writeHTML(b, false)
+	return b.String()
+}
+
+// String returns the markup for the node, as HTML does
+func (n *Node) String() string {
+	return n.HTML()
+}
+
+// writeHTML renders the node. The markup is kept valid even for trees not
+// built with the constructors: list items outside lists are wrapped in a
+// <ul>, other nodes inside lists are wrapped in an <li>, links inside links
+// are replaced by their content, and nodes of unknown kind are replaced by
+// their children.
+func (n *Node) writeHTML(b *strings.Builder, inLink bool) {
+	switch n.Kind {
+	case KindText:
+		b.WriteString(escaper.Replace(xmlText(n.Text)))
+		return
+	case KindMention:
+		if inLink {
+			b.WriteString(escaper.Replace(xmlText(PlainText(n))))
+			return
+		}
+		b.WriteString(`<a data-asana-gid="`)
+		b.WriteString(escaper.Replace(xmlText(n.GID)))
+		b.WriteString(`"/>`)
+		return
+	case KindImage:
+		b.WriteString(`<img data-asana-gid="`)
+		b.WriteString(escaper.Replace(xmlText(n.GID)))
+		b.WriteString(`"/>`)
+		return
+	case KindLink:
+		if inLink {
+			writeChildrenHTML(b, n, true)
+			return
+		}
+		b.WriteString(`<a href="`)
+		b.WriteString(escaper.Replace(xmlText(n.Href)))
+		b.WriteString(`">`)
+		inLink = true
+	default:
+		if !n.Kind.known() {
+			writeChildrenHTML(b, n, inLink)
+			return
+		}
+		b.WriteString("<")
+		b.WriteString(string(n.Kind))
+		b.WriteString(">")
+	}
+
+	writeChildrenHTML(b, n, inLink)
+
+	b.WriteString("</")
+	b.WriteString(string(n.Kind))
+	b.WriteString(">")
+}
+
+func writeChildrenHTML(b *strings.Builder, n *Node, inLink bool) {
+	isList := n.Kind == KindOrderedList || n.Kind == KindUnorderedList
+	looseItems := false
+
+	for _, child := range flatten(n.Children) {
+		switch {
+		case isList && child.Kind != KindListItem:
+			b.WriteString("<li>")
+			child.writeHTML(b, inLink)
+			b.WriteString("</li>")
+			continue
+		case !isList && child.Kind == KindListItem && !looseItems:
+			b.WriteString("<ul>")
+			looseItems = true
+		case !isList && child.Kind != KindListItem && looseItems:
+			b.WriteString("</ul>")
+			looseItems = false
+		}
+		child.writeHTML(b, inLink)
+	}
+	if looseItems {
+		b.WriteString("</ul>")
+	}
+}
+
+// flatten replaces nested bodies and nodes of unknown kind with their
+// children
+func flatten(nodes []*Node) []*Node {
+	result := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Kind == KindBody || !n.Kind.known() {
+			result = append(result, flatten(n.Children)...)
+			continue
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// Mentions returns the IDs of every object mentioned or linked by ID in the
+// document, in order of first appearance
+func Mentions(n *Node) []string {
+	var result []string
+	seen := map[string]bool{}
+	Walk(n, func(n *Node) bool {
+		if n.Kind == KindMention && n.GID != "" && !seen[n.GID] {
+			seen[n.GID] = true
+			result = append(result, n.GID)
+		}
+		return true
+	})
+	return result
+}
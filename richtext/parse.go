@@ -0,0 +1,209 @@
+package richtext
+
+import (
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// allowedAttributes lists the elements which Asana accepts and the
+// attributes each of them may carry. Attributes other than href and
+// data-asana-gid are generated by Asana and are dropped when parsing.
+var allowedAttributes = map[string][]string{
+	"body":       nil,
+	"strong":     nil,
+	"em":         nil,
+	"u":          nil,
+	"s":          nil,
+	"code":       nil,
+	"ol":         nil,
+	"ul":         nil,
+	"li":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"blockquote": nil,
+	"pre":        nil,
+	"a": {
+		"href", "data-asana-gid", "data-asana-type", "data-asana-dynamic",
+		"data-asana-accessible", "data-asana-project", "data-asana-tag",
+	},
+	"img": {
+		"data-asana-gid", "data-asana-type", "src", "alt",
+		"data-src-width", "data-src-height",
+		"data-thumbnail-url", "data-thumbnail-width", "data-thumbnail-height",
+	},
+}
+
+// Validate checks that text is acceptable to Asana as rich text. It must be
+// well-formed XML with a single <body> root element, and use only the
+// elements and attributes Asana supports.
+func Validate(text string) error {
+	_, err := Parse(text)
+	return err
+}
+
+// Parse reads Asana rich text into a document tree
+func Parse(text string) (*Node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.Strict = true
+
+	var root *Node
+	var stack []*Node
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid rich text")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var parent *Node
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			} else if root != nil {
+				return nil, errors.New("Invalid rich text: expected a single <body> element")
+			}
+
+			node, err := newElement(t, stack)
+			if err != nil {
+				return nil, err
+			}
+			if parent == nil {
+				root = node
+			} else {
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, errors.New("Invalid rich text: text outside of <body>")
+				}
+				continue
+			}
+			if err := appendText(stack[len(stack)-1], string(t)); err != nil {
+				return nil, err
+			}
+		case xml.ProcInst, xml.Directive:
+			return nil, errors.New("Invalid rich text: processing instructions and directives are not allowed")
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("Invalid rich text: expected a single <body> element")
+	}
+	return root, nil
+}
+
+// newElement creates the node for an element inside the open elements in
+// stack
+func newElement(element xml.StartElement, stack []*Node) (*Node, error) {
+	var parent *Node
+	if len(stack) > 0 {
+		parent = stack[len(stack)-1]
+	}
+
+	name := element.Name.Local
+	if element.Name.Space != "" {
+		return nil, errors.Errorf("Invalid rich text: namespaced element <%s:%s>", element.Name.Space, name)
+	}
+
+	if (parent == nil) != (name == "body") {
+		return nil, errors.Errorf("Invalid rich text: <%s> is not allowed here", name)
+	}
+
+	attributes, ok := allowedAttributes[name]
+	if !ok {
+		return nil, errors.Errorf("Invalid rich text: unsupported element <%s>", name)
+	}
+
+	node := &Node{Kind: Kind(name)}
+	for _, attr := range element.Attr {
+		if attr.Name.Space != "" || !slices.Contains(attributes, attr.Name.Local) {
+			return nil, errors.Errorf("Invalid rich text: unsupported attribute %q on <%s>", attr.Name.Local, name)
+		}
+		switch attr.Name.Local {
+		case "href":
+			node.Href = attr.Value
+		case "data-asana-gid":
+			node.GID = attr.Value
+		}
+	}
+
+	if node.Kind == KindLink && node.GID != "" {
+		node.Kind = KindMention
+	}
+	if node.Kind == KindLink || node.Kind == KindMention {
+		for _, ancestor := range stack {
+			if ancestor.Kind == KindLink || ancestor.Kind == KindMention {
+				return nil, errors.New("Invalid rich text: links cannot be nested")
+			}
+		}
+	}
+
+	isList := parent != nil && (parent.Kind == KindOrderedList || parent.Kind == KindUnorderedList)
+	if (node.Kind == KindListItem) != isList {
+		return nil, errors.Errorf("Invalid rich text: <%s> is not allowed in <%s>", name, parent.Kind)
+	}
+
+	return node, nil
+}
+
+// appendText adds text to a node, merging it with a preceding text node.
+// Whitespace between list items is dropped.
+func appendText(parent *Node, text string) error {
+	if parent.Kind == KindOrderedList || parent.Kind == KindUnorderedList {
+		if strings.TrimSpace(text) != "" {
+			return errors.Errorf("Invalid rich text: text is not allowed in <%s>", parent.Kind)
+		}
+		return nil
+	}
+
+	if n := len(parent.Children); n > 0 && parent.Children[n-1].Kind == KindText {
+		parent.Children[n-1].Text += text
+		return nil
+	}
+	parent.Children = append(parent.Children, Text(text))
+	return nil
+}
+
+// FindMentions returns the IDs of every object mentioned in text, in order
+// of first appearance. Unlike Parse it accepts any markup, such as the
+// html_text of a story which uses elements Parse rejects, and only looks at
+// the data-asana-gid attribute of <a> elements. Anything after markup which
+// cannot be read at all is ignored.
+func FindMentions(text string) []string {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var result []string
+	seen := map[string]bool{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return result
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || !strings.EqualFold(element.Name.Local, "a") {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if strings.EqualFold(attr.Name.Local, "data-asana-gid") && attr.Value != "" && !seen[attr.Value] {
+				seen[attr.Value] = true
+				result = append(result, attr.Value)
+			}
+		}
+	}
+}
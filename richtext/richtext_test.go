@@ -0,0 +1,197 @@
+package richtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuilder_HTML(t *testing.T) {
+	doc := Body(
+		Text("Hi "), Mention("123"), Text(" <see> "), Strong(Text("this")), Text("\n"),
+		UnorderedList(Text("one"), ListItem(Link("https://example.com?a=1&b=2", Text("two")))),
+	)
+
+	expected := `<body>Hi <a data-asana-gid="123"/> &lt;see&gt; <strong>this</strong>` + "\n" +
+		`<ul><li>one</li><li><a href="https://example.com?a=1&amp;b=2">two</a></li></ul></body>`
+	if html := doc.HTML(); html != expected {
+		t.Errorf("Expected\n%s\nbut saw\n%s", expected, html)
+	}
+
+	if err := Validate(doc.HTML()); err != nil {
+		t.Errorf("Expected built markup to be valid but saw %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`<body>Ping <a href="https://app.asana.com/0/profile/42" data-asana-gid="42" data-asana-type="user" data-asana-accessible="true">@Al</a><ol>
+<li>first</li>
+</ol></body>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected 3 children but saw %d", len(doc.Children))
+	}
+	if m := doc.Children[1]; m.Kind != KindMention || m.GID != "42" {
+		t.Errorf("Expected a mention of 42 but saw %+v", m)
+	}
+	if l := doc.Children[2]; l.Kind != KindOrderedList || len(l.Children) != 1 {
+		t.Errorf("Expected an ordered list with one item but saw %+v", l)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	invalid := []string{
+		`Hello`,
+		`<body><div>Hello</div></body>`,
+		`<body><li>loose</li></body>`,
+		`<body><ul>text</ul></body>`,
+		`<body><a onclick="x()">Hello</a></body>`,
+	}
+	for _, text := range invalid {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Expected %q to be invalid", text)
+		}
+	}
+}
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	html := `<body>Intro with <em>style</em>, <code>code</code> and <a href="https://example.com">a link</a> for <a data-asana-gid="42"/>` + "\n" +
+		`# not a heading, 1 * 2_3 C++` +
+		`<ul><li>zero<ul><li>nested</li><li>again</li></ul></li><li>half</li></ul>` +
+		`<h1>Title</h1>` +
+		`<ul><li>one<ol><li>nested <s>old</s></li><li><u>under</u></li></ol></li><li>two</li></ul>` +
+		`<blockquote>quoted` + "\n" + `lines</blockquote>` +
+		`<pre>x := 1` + "\n" + `y := 2</pre>` +
+		`Outro</body>`
+
+	doc, err := Parse(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markdown := ToMarkdown(doc)
+	if got := FromMarkdown(markdown).HTML(); got != html {
+		t.Errorf("Round trip through\n%s\nproduced\n%s\nexpected\n%s", markdown, got, html)
+	}
+}
+
+func TestFromMarkdown(t *testing.T) {
+	doc := FromMarkdown("## Plan\n* ship **it**\n* snake_case stays\n\nSee <https://example.com>")
+
+	expected := `<body><h2>Plan</h2><ul><li>ship <strong>it</strong></li><li>snake_case stays</li></ul>` + "\n" +
+		`See <a href="https://example.com">https://example.com</a></body>`
+	if html := doc.HTML(); html != expected {
+		t.Errorf("Expected\n%s\nbut saw\n%s", expected, html)
+	}
+}
+
+func TestBuilder_ValidMarkup(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      *Node
+		expected string
+	}{
+		{
+			"list item outside a list",
+			Body(Text("a"), ListItem(Text("x")), ListItem(Text("y")), Text("b")),
+			`<body>a<ul><li>x</li><li>y</li></ul>b</body>`,
+		},
+		{
+			"text outside a list item",
+			&Node{Kind: KindOrderedList, Children: []*Node{Text("x")}},
+			`<body><ol><li>x</li></ol></body>`,
+		},
+		{
+			"control characters",
+			Body(Text("a\x00b\x0bc\td\n"), Link("https://example.com/\x01", Text("e"))),
+			"<body>abc\td\n<a href=\"https://example.com/\">e</a></body>",
+		},
+		{
+			"link inside a link",
+			Link("https://a.example", Text("a "), Link("https://b.example", Strong(Text("b"))), Mention("1")),
+			`<body><a href="https://a.example">a <strong>b</strong></a></body>`,
+		},
+		{
+			"zero node",
+			&Node{},
+			`<body></body>`,
+		},
+		{
+			"unknown kinds",
+			&Node{Kind: KindUnorderedList, Children: []*Node{
+				{Kind: "p", Children: []*Node{ListItem(Text("x")), Text("y")}},
+				{Children: []*Node{Strong(Text("z"))}},
+			}},
+			`<body><ul><li>x</li><li>y</li><li><strong>z</strong></li></ul></body>`,
+		},
+		{
+			"link inside a link built directly",
+			&Node{Kind: KindLink, Href: "https://a.example", Children: []*Node{
+				{Kind: KindLink, Href: "https://b.example", Children: []*Node{Text("b")}},
+			}},
+			`<body><a href="https://a.example">b</a></body>`,
+		},
+	}
+
+	for _, test := range tests {
+		html := test.doc.HTML()
+		if html != test.expected {
+			t.Errorf("%s: expected\n%s\nbut saw\n%s", test.name, test.expected, html)
+		}
+		if err := Validate(html); err != nil {
+			t.Errorf("%s: expected valid markup but saw %v", test.name, err)
+		}
+	}
+}
+
+func TestParse_NestedLinks(t *testing.T) {
+	for _, text := range []string{
+		`<body><a href="https://a.example"><a href="https://b.example">b</a></a></body>`,
+		`<body><a href="https://a.example"><a data-asana-gid="1"/></a></body>`,
+	} {
+		if err := Validate(text); err == nil {
+			t.Errorf("Expected nested links to be invalid: %s", text)
+		}
+	}
+}
+
+func TestMarkdown_EdgeCases(t *testing.T) {
+	if s := ToMarkdown(nil); s != "" {
+		t.Errorf("Expected nil to convert to nothing but saw %q", s)
+	}
+
+	for _, text := range []string{"1) foo", "2. bar", "- baz"} {
+		doc := Body(Text(text))
+		if html := FromMarkdown(ToMarkdown(doc)).HTML(); html != doc.HTML() {
+			t.Errorf("Expected %q to stay text but saw %s", text, html)
+		}
+	}
+
+	for markdown, expected := range map[string]string{
+		"- ":              `<body><ul><li></li></ul></body>`,
+		"- a\n  - b\n- c": `<body><ul><li>a<ul><li>b</li></ul></li><li>c</li></ul></body>`,
+		"1. \nafter":      "<body><ol><li></li></ol>after</body>",
+		"[[a](https://a.example)](https://b.example)": `<body><a href="https://b.example">a</a></body>`,
+	} {
+		if html := FromMarkdown(markdown).HTML(); html != expected {
+			t.Errorf("Expected %q to convert to\n%s\nbut saw\n%s", markdown, expected, html)
+		}
+	}
+}
+
+func TestFindMentions(t *testing.T) {
+	for text, expected := range map[string]string{
+		"":                                     "",
+		"plain text":                           "",
+		`<body><a data-asana-gid="1"/></body>`: "1",
+		`<body><p><A DATA-ASANA-GID="1">x</A><img src=y><a data-asana-gid="2">`: "1 2",
+		`<body><a data-asana-gid="1">x</a> &bogus; <a data-asana-gid="1">`:      "1",
+		`<body><a data-asana-gid="1">x</a><a data-asana-gid="2"`:                "1",
+	} {
+		if mentions := strings.Join(FindMentions(text), " "); mentions != expected {
+			t.Errorf("Expected mentions %q in %s but saw %q", expected, text, mentions)
+		}
+	}
+}
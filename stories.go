@@ -3,6 +3,8 @@ package asana
 import (
 	"fmt"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // StoryBase contains the text of a story, as used when creating a new comment
@@ -29,12 +31,49 @@ type StoryBase struct {
 	IsPinned bool `json:"is_pinned,omitempty"`
 }
 
+// Validate checks that only one of text and html_text is set, and that any
+// html_text is valid Asana rich text
+func (s *StoryBase) Validate() error {
+	if s.Text != "" && s.HTMLText != "" {
+		return errors.New("Only one of text and html_text can be specified")
+	}
+	if s.HTMLText != "" {
+		return ValidateHTMLText(s.HTMLText)
+	}
+	return nil
+}
+
 type Dates struct {
 	DueOn   *Date      `json:"due_on,omitempty"`
 	DueAt   *time.Time `json:"due_at,omitempty"`
 	StartOn *Date      `json:"start_on,omitempty"`
 }
 
+// StoryTarget is the compact record of the object a story is associated with
+type StoryTarget struct {
+	ID              string `json:"gid,omitempty"`
+	ResourceType    string `json:"resource_type,omitempty"`
+	Name            string `json:"name,omitempty"`
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+}
+
+// Task returns the target as a Task, or nil if the story is not about a task
+func (t *StoryTarget) Task() *Task {
+	if t == nil || t.ResourceType != "task" {
+		return nil
+	}
+	return &Task{ID: t.ID, TaskBase: TaskBase{Name: t.Name, ResourceSubtype: t.ResourceSubtype}}
+}
+
+// Project returns the target as a Project, or nil if the story is not about
+// a project
+func (t *StoryTarget) Project() *Project {
+	if t == nil || t.ResourceType != "project" {
+		return nil
+	}
+	return &Project{ID: t.ID, ProjectBase: ProjectBase{Name: t.Name}}
+}
+
 type Dependency struct {
 	ID              string `json:"gid,omitempty"`
 	ResourceType    string `json:"resource_type,omitempty"`
@@ -87,13 +126,30 @@ type StorySubtypeFields struct {
 	// Present for added_to_tag, removed_from_tag
 	Tag *Tag `json:"tag,omitempty"`
 
-	// Present for text_custom_field_changed, number_custom_field_changed, enum_custom_field_changed
-	OldTextValue   string     `json:"old_text_value,omitempty"`
-	NewTextValue   string     `json:"new_text_value,omitempty"`
-	OldNumberValue float64    `json:"old_number_value,omitempty"`
-	NewNumberValue float64    `json:"new_number_value,omitempty"`
-	OldEnumValue   *EnumValue `json:"old_enum_value,omitempty"`
-	NewEnumValue   *EnumValue `json:"new_enum_value,omitempty"`
+	// Present for all *_custom_field_changed subtypes
+	CustomField *CustomField `json:"custom_field,omitempty"`
+
+	// Present for text_custom_field_changed, number_custom_field_changed,
+	// enum_custom_field_changed, multi_enum_custom_field_changed,
+	// date_custom_field_changed and people_custom_field_changed. A nil value
+	// means the field was empty, so a change to or from zero can be told
+	// apart from a field which was not set.
+	OldTextValue       *string      `json:"old_text_value,omitempty"`
+	NewTextValue       *string      `json:"new_text_value,omitempty"`
+	OldNumberValue     *float64     `json:"old_number_value,omitempty"`
+	NewNumberValue     *float64     `json:"new_number_value,omitempty"`
+	OldEnumValue       *EnumValue   `json:"old_enum_value,omitempty"`
+	NewEnumValue       *EnumValue   `json:"new_enum_value,omitempty"`
+	OldMultiEnumValues []*EnumValue `json:"old_multi_enum_values,omitempty"`
+	NewMultiEnumValues []*EnumValue `json:"new_multi_enum_values,omitempty"`
+	OldDateValue       *DateValue   `json:"old_date_value,omitempty"`
+	NewDateValue       *DateValue   `json:"new_date_value,omitempty"`
+	OldPeopleValue     []*User      `json:"old_people_value,omitempty"`
+	NewPeopleValue     []*User      `json:"new_people_value,omitempty"`
+
+	// Present for approval_status_changed
+	OldApprovalStatus string `json:"old_approval_status,omitempty"`
+	NewApprovalStatus string `json:"new_approval_status,omitempty"`
 
 	// Present for duplicate_merged, marked_duplicate, duplicate_unmerged
 	DuplicateOf *Task `json:"duplicate_of,omitempty"`
@@ -137,44 +193,73 @@ type Story struct {
 	// The user who created the story.
 	CreatedBy *User `json:"created_by,omitempty"`
 
-	// Read-only. The object this story is associated with. Currently may only
-	// be a task.
-	Target *Task `json:"target,omitempty"`
+	// Read-only. The object this story is associated with, such as a task or
+	// a project.
+	Target *StoryTarget `json:"target,omitempty"`
 
 	// Read-only. The component of the Asana product the user used to trigger
 	// the story.
 	Source string `json:"source,omitempty"`
 
 	// Read-only. The type of story. This provides fine-grained information about what
-	// triggered the story’s creation. There are many story subtypes, so inspect the
-	// data returned from Asana API to find the value for your use case.
-	ResourceSubtype string `json:"resource_subtype,omitempty"`
+	// triggered the story’s creation. Use AsEvent to decode the subtype
+	// specific fields into a typed value.
+	ResourceSubtype StorySubtype `json:"resource_subtype,omitempty"`
 
 	// A union of all possible subtype fields
 	StorySubtypeFields
 }
 
-// Stories lists all stories attached to a task
-func (t *Task) Stories(client *Client, opts ...*Options) ([]*Story, *NextPage, error) {
-	client.trace("Listing stories for %q", t.Name)
+// Fetch loads the full details for this Story
+func (s *Story) Fetch(client *Client, opts ...*Options) error {
+	client.trace("Loading details for story %s", s.ID)
+
+	_, err := client.get(fmt.Sprintf("/stories/%s", s.ID), nil, s, opts...)
+	return err
+}
+
+// storiesPath returns the path of the stories on a target. Targets without
+// a resource type are taken to be tasks.
+func storiesPath(target *StoryTarget) string {
+	resourceType := target.ResourceType
+	if resourceType == "" {
+		resourceType = "task"
+	}
+	return fmt.Sprintf("/%ss/%s/stories", resourceType, target.ID)
+}
+
+// Stories lists the stories on any object which has them, such as the
+// Target of another story
+func (c *Client) Stories(target *StoryTarget, opts ...*Options) ([]*Story, *NextPage, error) {
+	c.trace("Listing stories for %s %s", target.ResourceType, target.ID)
 
 	var result []*Story
 
 	// Make the request
-	nextPage, err := client.get(fmt.Sprintf("/tasks/%s/stories", t.ID), nil, &result, opts...)
+	nextPage, err := c.get(storiesPath(target), nil, &result, opts...)
 	return result, nextPage, err
 }
 
-// CreateComment adds a comment story to a task
-func (t *Task) CreateComment(client *Client, story *StoryBase) (*Story, error) {
-	client.info("Creating comment for task %q", t.Name)
+// CreateComment adds a comment story to any object which has stories
+func (c *Client) CreateComment(target *StoryTarget, story *StoryBase) (*Story, error) {
+	c.info("Creating comment for %s %s", target.ResourceType, target.ID)
 
 	result := &Story{}
 
-	err := client.post(fmt.Sprintf("/tasks/%s/stories", t.ID), story, result)
+	err := c.post(storiesPath(target), story, result)
 	return result, err
 }
 
+// Stories lists all stories attached to a task
+func (t *Task) Stories(client *Client, opts ...*Options) ([]*Story, *NextPage, error) {
+	return client.Stories(&StoryTarget{ID: t.ID, ResourceType: "task"}, opts...)
+}
+
+// CreateComment adds a comment story to a task
+func (t *Task) CreateComment(client *Client, story *StoryBase) (*Story, error) {
+	return client.CreateComment(&StoryTarget{ID: t.ID, ResourceType: "task"}, story)
+}
+
 // UpdateStory updates the story and returns the full record for the updated story.
 // Only comment stories can have their text updated, and only comment stories and attachment stories can be pinned.
 // Only one of text and html_text can be specified.
@@ -193,3 +278,68 @@ func (s *Story) Delete(client *Client) error {
 	err := client.delete(fmt.Sprintf("/stories/%s", s.ID))
 	return err
 }
+
+// Pin pins this story to the top of its target. Only comment and attachment
+// stories can be pinned.
+func (s *Story) Pin(client *Client) error {
+	return s.setPinned(client, true)
+}
+
+// Unpin removes this story from the pinned stories of its target
+func (s *Story) Unpin(client *Client) error {
+	return s.setPinned(client, false)
+}
+
+func (s *Story) setPinned(client *Client, pinned bool) error {
+	client.info("Setting pinned to %v for story %s", pinned, s.ID)
+
+	// Custom encoding needed, as IsPinned is omitted when false
+	m := map[string]interface{}{
+		"is_pinned": pinned,
+	}
+
+	return client.put(fmt.Sprintf("/stories/%s", s.ID), m, s)
+}
+
+// Reaction is an emoji reaction left by a user on a story or status update
+type Reaction struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The base emoji of the reaction, without any skin tone
+	// variation.
+	EmojiBase string `json:"emoji_base,omitempty"`
+
+	// Read-only. The emoji as displayed, including any variation.
+	Variant string `json:"variant,omitempty"`
+
+	// Read-only. The user who reacted.
+	User *User `json:"user,omitempty"`
+}
+
+type reactionsRequestParams struct {
+	Target    string `url:"target"`
+	EmojiBase string `url:"emoji_base"`
+}
+
+// Reactions lists the reactions with the given base emoji on a story or
+// status update. Reactions can only be read through the API, not added or
+// removed.
+func (c *Client) Reactions(target, emojiBase string, opts ...*Options) ([]*Reaction, *NextPage, error) {
+	c.trace("Listing %s reactions on %s", emojiBase, target)
+
+	var result []*Reaction
+
+	// Make the request
+	query := reactionsRequestParams{
+		Target:    target,
+		EmojiBase: emojiBase,
+	}
+	nextPage, err := c.get("/reactions", query, &result, opts...)
+	return result, nextPage, err
+}
+
+// Reactions lists the reactions with the given base emoji on this story
+func (s *Story) Reactions(client *Client, emojiBase string, opts ...*Options) ([]*Reaction, *NextPage, error) {
+	return client.Reactions(s.ID, emojiBase, opts...)
+}
@@ -0,0 +1,219 @@
+package asana
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+)
+
+func TestStory_AsEvent_NumberChangedToZero(t *testing.T) {
+	story := &Story{}
+	if err := json.Unmarshal([]byte(`
+{
+	"gid": "1",
+	"resource_subtype": "number_custom_field_changed",
+	"custom_field": {"gid": "2", "name": "Points"},
+	"old_number_value": 3,
+	"new_number_value": 0
+}
+`), story); err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := story.AsEvent().(*NumberCustomFieldChangedStory)
+	if !ok {
+		t.Fatalf("Expected a NumberCustomFieldChangedStory but saw %T", story.AsEvent())
+	}
+	if event.OldValue == nil || *event.OldValue != 3 {
+		t.Errorf("Expected old value 3 but saw %v", event.OldValue)
+	}
+	if event.NewValue == nil || *event.NewValue != 0 {
+		t.Errorf("Expected new value to be a pointer to zero but saw %v", event.NewValue)
+	}
+	if event.Source() != story {
+		t.Error("Expected the event to reference its story")
+	}
+}
+
+func TestStory_AsEvent_Unknown(t *testing.T) {
+	story := &Story{ResourceSubtype: "something_new"}
+
+	event, ok := story.AsEvent().(*UnknownStory)
+	if !ok || event.Subtype != "something_new" {
+		t.Errorf("Expected an UnknownStory but saw %#v", story.AsEvent())
+	}
+}
+
+func TestStory_Fetch(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/stories/1").
+		Reply(200).
+		JSON(o{"data": o{
+			"gid":              "1",
+			"resource_subtype": "comment_added",
+			"text":             "Done",
+			"is_pinned":        true,
+			"target":           o{"gid": "2", "resource_type": "project", "name": "Launch"},
+		}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	story := &Story{ID: "1"}
+	if err := story.Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+
+	if story.Text != "Done" || !story.IsPinned {
+		t.Errorf("Unexpected story %+v", story)
+	}
+	if p := story.Target.Project(); p == nil || p.ID != "2" || story.Target.Task() != nil {
+		t.Errorf("Expected a project target but saw %+v", story.Target)
+	}
+}
+
+func TestStory_Pin(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Put("/stories/1").
+		BodyString(`"data":\{"is_pinned":true\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "1", "is_pinned": true}})
+	gock.New("https://app.asana.com/api/1.0").
+		Put("/stories/1").
+		BodyString(`"data":\{"is_pinned":false\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "1", "is_pinned": false}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	story := &Story{ID: "1"}
+	if err := story.Pin(client); err != nil {
+		t.Fatal(err)
+	}
+	if !story.IsPinned {
+		t.Error("Expected the story to be pinned")
+	}
+	if err := story.Unpin(client); err != nil {
+		t.Fatal(err)
+	}
+	if story.IsPinned {
+		t.Error("Expected the story to be unpinned")
+	}
+	if !gock.IsDone() {
+		t.Error("Expected is_pinned to be sent even when false")
+	}
+}
+
+func TestClient_Stories(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/projects/2/stories").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "1", "resource_subtype": "comment_added"}}})
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/projects/2/stories").
+		BodyString(`"data":\{"html_text":"[^"]*Summary[^"]*","is_pinned":true\}`).
+		Reply(201).
+		JSON(o{"data": o{"gid": "3"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	target := &StoryTarget{ID: "2", ResourceType: "project"}
+
+	stories, _, err := client.Stories(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 1 || stories[0].ID != "1" {
+		t.Errorf("Unexpected stories %+v", stories)
+	}
+
+	story, err := client.CreateComment(target, &StoryBase{HTMLText: "<body>Summary</body>", IsPinned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if story.ID != "3" {
+		t.Errorf("Expected story 3 but saw %s", story.ID)
+	}
+
+	if _, err := client.CreateComment(target, &StoryBase{HTMLText: "<body><div>x</div></body>"}); err == nil {
+		t.Error("Expected invalid rich text to be rejected")
+	}
+}
+
+func TestStory_Reactions(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/reactions").
+		MatchParams(map[string]string{"target": "1", "emoji_base": "👍"}).
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "5", "emoji_base": "👍", "variant": "👍🏽", "user": o{"gid": "9"}}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	story := &Story{ID: "1"}
+
+	reactions, _, err := story.Reactions(client, "👍")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reactions) != 1 || reactions[0].Variant != "👍🏽" || reactions[0].User.ID != "9" {
+		t.Errorf("Unexpected reactions %+v", reactions)
+	}
+}
+
+func TestStory_AsEvent_DateChanges(t *testing.T) {
+	var stories []*Story
+	if err := json.Unmarshal([]byte(`
+[
+	{"gid": "1", "resource_subtype": "due_date_changed", "created_at": "2024-01-01T09:00:00Z",
+	 "old_dates": {"start_on": "2024-01-01", "due_on": "2024-01-05"},
+	 "new_dates": {"start_on": "2024-01-01", "due_on": "2024-01-08"}},
+	{"gid": "2", "resource_subtype": "start_date_changed", "created_at": "2024-01-01T10:00:00Z",
+	 "old_dates": {"start_on": "2024-01-01", "due_on": "2024-01-08"},
+	 "new_dates": {"start_on": "2024-01-02", "due_on": "2024-01-08"}}
+]
+`), &stories); err != nil {
+		t.Fatal(err)
+	}
+
+	due, ok := stories[0].AsEvent().(*DueDateChangedStory)
+	if !ok {
+		t.Fatalf("Expected a DueDateChangedStory but saw %T", stories[0].AsEvent())
+	}
+	if due.NewDates == nil || dateString(due.NewDates.DueOn) != "2024-01-08" {
+		t.Errorf("Unexpected new dates %+v", due.NewDates)
+	}
+
+	start, ok := stories[1].AsEvent().(*StartDateChangedStory)
+	if !ok {
+		t.Fatalf("Expected a StartDateChangedStory but saw %T", stories[1].AsEvent())
+	}
+	if start.OldDates == nil || dateString(start.OldDates.StartOn) != "2024-01-01" ||
+		start.NewDates == nil || dateString(start.NewDates.StartOn) != "2024-01-02" {
+		t.Errorf("Unexpected dates %+v -> %+v", start.OldDates, start.NewDates)
+	}
+
+	// Both kinds of change are replayed by task history
+	task := &Task{ID: "3"}
+	task.StartOn, task.DueOn = start.NewDates.StartOn, start.NewDates.DueOn
+	h := NewTaskHistory(task, stories)
+	if dates := h.StateAt(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)).Dates; dateString(dates.DueOn) != "2024-01-05" {
+		t.Errorf("Expected the original due date but saw %+v", dates)
+	}
+	if dates := h.StateAt(time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)).Dates; dateString(dates.StartOn) != "2024-01-01" ||
+		dateString(dates.DueOn) != "2024-01-08" {
+		t.Errorf("Expected the original start date and new due date but saw %+v", dates)
+	}
+}
+
+func dateString(d *Date) string {
+	if d == nil {
+		return ""
+	}
+	return time.Time(*d).Format("2006-01-02")
+}
@@ -0,0 +1,349 @@
+package asana
+
+// StorySubtype identifies the action which created a Story
+type StorySubtype string
+
+// StorySubtypes for Story.ResourceSubtype
+const (
+	StorySubtypeCommentAdded    StorySubtype = "comment_added"
+	StorySubtypeCommentLiked    StorySubtype = "comment_liked"
+	StorySubtypeAttachmentAdded StorySubtype = "attachment_added"
+	StorySubtypeAttachmentLiked StorySubtype = "attachment_liked"
+	StorySubtypeCompletionLiked StorySubtype = "completion_liked"
+	StorySubtypeLiked           StorySubtype = "liked"
+
+	StorySubtypeAssigned      StorySubtype = "assigned"
+	StorySubtypeUnassigned    StorySubtype = "unassigned"
+	StorySubtypeFollowerAdded StorySubtype = "follower_added"
+
+	StorySubtypeNameChanged            StorySubtype = "name_changed"
+	StorySubtypeNotesChanged           StorySubtype = "notes_changed"
+	StorySubtypeResourceSubtypeChanged StorySubtype = "resource_subtype_changed"
+	StorySubtypeApprovalStatusChanged  StorySubtype = "approval_status_changed"
+
+	StorySubtypeMarkedComplete   StorySubtype = "marked_complete"
+	StorySubtypeMarkedIncomplete StorySubtype = "marked_incomplete"
+
+	StorySubtypeDueDateChanged   StorySubtype = "due_date_changed"
+	StorySubtypeStartDateChanged StorySubtype = "start_date_changed"
+
+	StorySubtypeSectionChanged     StorySubtype = "section_changed"
+	StorySubtypeAddedToProject     StorySubtype = "added_to_project"
+	StorySubtypeRemovedFromProject StorySubtype = "removed_from_project"
+	StorySubtypeAddedToTag         StorySubtype = "added_to_tag"
+	StorySubtypeRemovedFromTag     StorySubtype = "removed_from_tag"
+	StorySubtypeAddedToTask        StorySubtype = "added_to_task"
+	StorySubtypeRemovedFromTask    StorySubtype = "removed_from_task"
+
+	StorySubtypeTextCustomFieldChanged      StorySubtype = "text_custom_field_changed"
+	StorySubtypeNumberCustomFieldChanged    StorySubtype = "number_custom_field_changed"
+	StorySubtypeEnumCustomFieldChanged      StorySubtype = "enum_custom_field_changed"
+	StorySubtypeMultiEnumCustomFieldChanged StorySubtype = "multi_enum_custom_field_changed"
+	StorySubtypeDateCustomFieldChanged      StorySubtype = "date_custom_field_changed"
+	StorySubtypePeopleCustomFieldChanged    StorySubtype = "people_custom_field_changed"
+
+	StorySubtypeDependencyAdded            StorySubtype = "dependency_added"
+	StorySubtypeDependencyRemoved          StorySubtype = "dependency_removed"
+	StorySubtypeDependentAdded             StorySubtype = "dependent_added"
+	StorySubtypeDependentRemoved           StorySubtype = "dependent_removed"
+	StorySubtypeDependencyMarkedComplete   StorySubtype = "dependency_marked_complete"
+	StorySubtypeDependencyMarkedIncomplete StorySubtype = "dependency_marked_incomplete"
+	StorySubtypeDependencyDueDateChanged   StorySubtype = "dependency_due_date_changed"
+
+	StorySubtypeDuplicated        StorySubtype = "duplicated"
+	StorySubtypeMarkedDuplicate   StorySubtype = "marked_duplicate"
+	StorySubtypeDuplicateMerged   StorySubtype = "duplicate_merged"
+	StorySubtypeDuplicateUnmerged StorySubtype = "duplicate_unmerged"
+)
+
+func (s StorySubtype) String() string {
+	return string(s)
+}
+
+// StoryEvent is implemented by the typed values returned from Story.AsEvent.
+// Use a type switch on the concrete types to handle each kind of story.
+type StoryEvent interface {
+	// Source returns the story the event was decoded from
+	Source() *Story
+}
+
+type storyEvent struct {
+	story *Story
+}
+
+func (e storyEvent) Source() *Story {
+	return e.story
+}
+
+// CommentStory is a comment_added story
+type CommentStory struct {
+	storyEvent
+	Text     string
+	HTMLText string
+	IsEdited bool
+	IsPinned bool
+}
+
+// LikedStory is a comment_liked, attachment_liked, completion_liked or
+// liked story. Story or Attachment is set to the liked object when known.
+type LikedStory struct {
+	storyEvent
+	Story      *Story
+	Attachment *Attachment
+}
+
+// AttachmentAddedStory is an attachment_added story
+type AttachmentAddedStory struct {
+	storyEvent
+}
+
+// AssignedStory is an assigned story
+type AssignedStory struct {
+	storyEvent
+	Assignee *User
+}
+
+// UnassignedStory is an unassigned story
+type UnassignedStory struct {
+	storyEvent
+}
+
+// FollowerAddedStory is a follower_added story
+type FollowerAddedStory struct {
+	storyEvent
+	Follower *User
+}
+
+// NameChangedStory is a name_changed story
+type NameChangedStory struct {
+	storyEvent
+	OldName string
+	NewName string
+}
+
+// NotesChangedStory is a notes_changed story
+type NotesChangedStory struct {
+	storyEvent
+}
+
+// ResourceSubtypeChangedStory is a resource_subtype_changed story, such as a
+// task becoming a milestone
+type ResourceSubtypeChangedStory struct {
+	storyEvent
+	OldResourceSubtype string
+	NewResourceSubtype string
+}
+
+// ApprovalStatusChangedStory is an approval_status_changed story
+type ApprovalStatusChangedStory struct {
+	storyEvent
+	OldApprovalStatus string
+	NewApprovalStatus string
+}
+
+// CompletionChangedStory is a marked_complete or marked_incomplete story
+type CompletionChangedStory struct {
+	storyEvent
+	Completed bool
+}
+
+// DueDateChangedStory is a due_date_changed story. OldDates is nil when no
+// dates were previously set, and NewDates is nil when the dates were
+// removed.
+type DueDateChangedStory struct {
+	storyEvent
+	OldDates *Dates
+	NewDates *Dates
+}
+
+// StartDateChangedStory is a start_date_changed story. Like
+// DueDateChangedStory it records all of the task's dates before and after
+// the change.
+type StartDateChangedStory struct {
+	storyEvent
+	OldDates *Dates
+	NewDates *Dates
+}
+
+// SectionChangedStory is a section_changed story
+type SectionChangedStory struct {
+	storyEvent
+	OldSection *Section
+	NewSection *Section
+}
+
+// ProjectChangedStory is an added_to_project or removed_from_project story
+type ProjectChangedStory struct {
+	storyEvent
+	Project *Project
+	Added   bool
+}
+
+// TagChangedStory is an added_to_tag or removed_from_tag story
+type TagChangedStory struct {
+	storyEvent
+	Tag   *Tag
+	Added bool
+}
+
+// ParentChangedStory is an added_to_task or removed_from_task story, for a
+// subtask whose parent changed
+type ParentChangedStory struct {
+	storyEvent
+	Task  *Task
+	Added bool
+}
+
+// TextCustomFieldChangedStory is a text_custom_field_changed story
+type TextCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValue    *string
+	NewValue    *string
+}
+
+// NumberCustomFieldChangedStory is a number_custom_field_changed story
+type NumberCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValue    *float64
+	NewValue    *float64
+}
+
+// EnumCustomFieldChangedStory is an enum_custom_field_changed story
+type EnumCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValue    *EnumValue
+	NewValue    *EnumValue
+}
+
+// MultiEnumCustomFieldChangedStory is a multi_enum_custom_field_changed story
+type MultiEnumCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValues   []*EnumValue
+	NewValues   []*EnumValue
+}
+
+// DateCustomFieldChangedStory is a date_custom_field_changed story
+type DateCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValue    *DateValue
+	NewValue    *DateValue
+}
+
+// PeopleCustomFieldChangedStory is a people_custom_field_changed story
+type PeopleCustomFieldChangedStory struct {
+	storyEvent
+	CustomField *CustomField
+	OldValue    []*User
+	NewValue    []*User
+}
+
+// DependencyStory is one of the dependency_* or dependent_* stories. The
+// Subtype distinguishes additions, removals and completion changes.
+type DependencyStory struct {
+	storyEvent
+	Subtype    StorySubtype
+	Dependency *Dependency
+}
+
+// DependencyDueDateChangedStory is a dependency_due_date_changed story
+type DependencyDueDateChangedStory struct {
+	storyEvent
+	Dependency *Dependency
+	NewDates   *Dates
+}
+
+// DuplicateStory is a duplicated, marked_duplicate, duplicate_merged or
+// duplicate_unmerged story. DuplicatedFrom is set for duplicated stories and
+// DuplicateOf for the others.
+type DuplicateStory struct {
+	storyEvent
+	Subtype        StorySubtype
+	DuplicateOf    *Task
+	DuplicatedFrom *Task
+}
+
+// UnknownStory is returned for subtypes this package does not decode
+type UnknownStory struct {
+	storyEvent
+	Subtype StorySubtype
+}
+
+// AsEvent decodes the subtype specific fields of this story into a typed
+// value
+func (s *Story) AsEvent() StoryEvent {
+	e := storyEvent{story: s}
+
+	switch s.ResourceSubtype {
+	case StorySubtypeCommentAdded:
+		return &CommentStory{storyEvent: e, Text: s.Text, HTMLText: s.HTMLText, IsEdited: s.IsEdited, IsPinned: s.IsPinned}
+	case StorySubtypeCommentLiked, StorySubtypeAttachmentLiked, StorySubtypeCompletionLiked, StorySubtypeLiked:
+		return &LikedStory{storyEvent: e, Story: s.Story, Attachment: s.Attachment}
+	case StorySubtypeAttachmentAdded:
+		return &AttachmentAddedStory{storyEvent: e}
+
+	case StorySubtypeAssigned:
+		return &AssignedStory{storyEvent: e, Assignee: s.Assignee}
+	case StorySubtypeUnassigned:
+		return &UnassignedStory{storyEvent: e}
+	case StorySubtypeFollowerAdded:
+		return &FollowerAddedStory{storyEvent: e, Follower: s.Follower}
+
+	case StorySubtypeNameChanged:
+		return &NameChangedStory{storyEvent: e, OldName: s.OldName, NewName: s.NewName}
+	case StorySubtypeNotesChanged:
+		return &NotesChangedStory{storyEvent: e}
+	case StorySubtypeResourceSubtypeChanged:
+		return &ResourceSubtypeChangedStory{storyEvent: e, OldResourceSubtype: s.OldResourceSubtype, NewResourceSubtype: s.NewResourceSubtype}
+	case StorySubtypeApprovalStatusChanged:
+		return &ApprovalStatusChangedStory{storyEvent: e, OldApprovalStatus: s.OldApprovalStatus, NewApprovalStatus: s.NewApprovalStatus}
+
+	case StorySubtypeMarkedComplete:
+		return &CompletionChangedStory{storyEvent: e, Completed: true}
+	case StorySubtypeMarkedIncomplete:
+		return &CompletionChangedStory{storyEvent: e, Completed: false}
+
+	case StorySubtypeDueDateChanged:
+		return &DueDateChangedStory{storyEvent: e, OldDates: s.OldDates, NewDates: s.NewDates}
+	case StorySubtypeStartDateChanged:
+		return &StartDateChangedStory{storyEvent: e, OldDates: s.OldDates, NewDates: s.NewDates}
+
+	case StorySubtypeSectionChanged:
+		return &SectionChangedStory{storyEvent: e, OldSection: s.OldSection, NewSection: s.NewSection}
+	case StorySubtypeAddedToProject, StorySubtypeRemovedFromProject:
+		return &ProjectChangedStory{storyEvent: e, Project: s.Project, Added: s.ResourceSubtype == StorySubtypeAddedToProject}
+	case StorySubtypeAddedToTag, StorySubtypeRemovedFromTag:
+		return &TagChangedStory{storyEvent: e, Tag: s.Tag, Added: s.ResourceSubtype == StorySubtypeAddedToTag}
+	case StorySubtypeAddedToTask, StorySubtypeRemovedFromTask:
+		return &ParentChangedStory{storyEvent: e, Task: s.Task, Added: s.ResourceSubtype == StorySubtypeAddedToTask}
+
+	case StorySubtypeTextCustomFieldChanged:
+		return &TextCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValue: s.OldTextValue, NewValue: s.NewTextValue}
+	case StorySubtypeNumberCustomFieldChanged:
+		return &NumberCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValue: s.OldNumberValue, NewValue: s.NewNumberValue}
+	case StorySubtypeEnumCustomFieldChanged:
+		return &EnumCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValue: s.OldEnumValue, NewValue: s.NewEnumValue}
+	case StorySubtypeMultiEnumCustomFieldChanged:
+		return &MultiEnumCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValues: s.OldMultiEnumValues, NewValues: s.NewMultiEnumValues}
+	case StorySubtypeDateCustomFieldChanged:
+		return &DateCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValue: s.OldDateValue, NewValue: s.NewDateValue}
+	case StorySubtypePeopleCustomFieldChanged:
+		return &PeopleCustomFieldChangedStory{storyEvent: e, CustomField: s.CustomField, OldValue: s.OldPeopleValue, NewValue: s.NewPeopleValue}
+
+	case StorySubtypeDependencyAdded, StorySubtypeDependencyRemoved,
+		StorySubtypeDependentAdded, StorySubtypeDependentRemoved,
+		StorySubtypeDependencyMarkedComplete, StorySubtypeDependencyMarkedIncomplete:
+		return &DependencyStory{storyEvent: e, Subtype: s.ResourceSubtype, Dependency: s.Dependency}
+	case StorySubtypeDependencyDueDateChanged:
+		return &DependencyDueDateChangedStory{storyEvent: e, Dependency: s.Dependency, NewDates: s.NewDates}
+
+	case StorySubtypeDuplicated, StorySubtypeMarkedDuplicate, StorySubtypeDuplicateMerged, StorySubtypeDuplicateUnmerged:
+		return &DuplicateStory{storyEvent: e, Subtype: s.ResourceSubtype, DuplicateOf: s.DuplicateOf, DuplicatedFrom: s.DuplicatedFrom}
+	}
+
+	return &UnknownStory{storyEvent: e, Subtype: s.ResourceSubtype}
+}
@@ -1,6 +1,7 @@
 package asana
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -106,3 +107,132 @@ func (w *Workspace) CreateTag(client *Client, tag *TagBase, options ...*Options)
 
 	return result, nil
 }
+
+// CreateTagRequest represents a request to create a new tag
+type CreateTagRequest struct {
+	TagBase
+
+	// Required: The workspace or organization to create the tag in.
+	Workspace string `json:"workspace"`
+
+	// Array of user IDs following this tag.
+	Followers []string `json:"followers,omitempty"`
+}
+
+// CreateTag adds a new tag to the workspace named in the request, without
+// requiring a Workspace object
+func (c *Client) CreateTag(tag *CreateTagRequest, options ...*Options) (*Tag, error) {
+	c.info("Creating tag %q in workspace %s\n", tag.Name, tag.Workspace)
+
+	result := &Tag{}
+
+	err := c.post("/tags", tag, result, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateTagRequest represents a request to update a tag. Only the fields
+// which are set are changed, so set Notes to an empty string to clear the
+// notes, and Color to an empty string to remove the color.
+type UpdateTagRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Notes *string `json:"notes,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// MarshalJSON sends an empty color as null, which the API requires to
+// remove it
+func (r *UpdateTagRequest) MarshalJSON() ([]byte, error) {
+	type request UpdateTagRequest
+	value := struct {
+		*request
+		Color json.RawMessage `json:"color,omitempty"`
+	}{request: (*request)(r)}
+
+	if r.Color != nil {
+		if *r.Color == "" {
+			value.Color = json.RawMessage("null")
+		} else {
+			color, err := json.Marshal(*r.Color)
+			if err != nil {
+				return nil, err
+			}
+			value.Color = color
+		}
+	}
+	return json.Marshal(value)
+}
+
+// Update applies new values to a Tag record. Only the name, color and notes
+// of a tag can be changed.
+func (t *Tag) Update(client *Client, update *UpdateTagRequest, options ...*Options) error {
+	client.trace("Updating tag %q", t.Name)
+
+	return client.put(fmt.Sprintf("/tags/%s", t.ID), update, t, options...)
+}
+
+// Delete removes this tag. The tag is removed from every task it was
+// attached to.
+func (t *Tag) Delete(client *Client) error {
+	client.info("Deleting tag %q", t.Name)
+
+	return client.delete(fmt.Sprintf("/tags/%s", t.ID))
+}
+
+// Tasks returns a list of tasks associated with this tag
+func (t *Tag) Tasks(client *Client, options ...*Options) ([]*Task, *NextPage, error) {
+	client.trace("Listing tasks with tag %q", t.Name)
+
+	var result []*Task
+
+	// Make the request
+	nextPage, err := client.get(fmt.Sprintf("/tags/%s/tasks", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// AllTasks repeatedly pages through all tasks associated with this tag
+func (t *Tag) AllTasks(client *Client, options ...*Options) ([]*Task, error) {
+	var allTasks []*Task
+	nextPage := &NextPage{}
+
+	var tasks []*Task
+	var err error
+
+	for nextPage != nil {
+		page := &Options{
+			Limit:  100,
+			Offset: nextPage.Offset,
+		}
+
+		allOptions := append([]*Options{page}, options...)
+		tasks, nextPage, err = t.Tasks(client, allOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		allTasks = append(allTasks, tasks...)
+	}
+	return allTasks, nil
+}
+
+// FindTags looks up tags in this workspace whose names match the query
+// using the typeahead search. Results are ordered by relevance and at most
+// count tags are returned; a count of zero uses the API default of 20. Only
+// the ID and name of each tag are loaded.
+func (w *Workspace) FindTags(client *Client, query string, count int, options ...*Options) ([]*Tag, error) {
+	results, err := w.Typeahead(client, TypeaheadTag, query, count, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*Tag, 0, len(results))
+	for _, r := range results {
+		if tag := r.Tag(); tag != nil {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,139 @@
+package asana
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+)
+
+func TestUpdateTagRequest_JSON(t *testing.T) {
+	tests := []struct {
+		request  *UpdateTagRequest
+		expected string
+	}{
+		{&UpdateTagRequest{}, `{}`},
+		{&UpdateTagRequest{Name: String("Urgent")}, `{"name":"Urgent"}`},
+		{&UpdateTagRequest{Notes: String(""), Color: String("")}, `{"notes":"","color":null}`},
+		{&UpdateTagRequest{Color: String("dark-red")}, `{"color":"dark-red"}`},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("Expected %s but saw %s", test.expected, data)
+		}
+	}
+}
+
+func TestTag_Update(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Put("/tags/1").
+		BodyString(`"data":\{"notes":"","color":null\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "1", "name": "Urgent"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	tag := &Tag{ID: "1", TagBase: TagBase{Name: "Urgent", Notes: "Old", Color: "dark-red"}}
+
+	if err := tag.Update(client, &UpdateTagRequest{Notes: String(""), Color: String("")}); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected the notes and color to be cleared")
+	}
+}
+
+func TestClient_CreateTag(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/tags").
+		BodyString(`"data":\{"name":"Urgent","color":"dark-red","workspace":"1","followers":\["5"\]\}`).
+		Reply(201).
+		JSON(o{"data": o{"gid": "2", "name": "Urgent", "workspace": o{"gid": "1"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	tag, err := client.CreateTag(&CreateTagRequest{
+		TagBase:   TagBase{Name: "Urgent", Color: "dark-red"},
+		Workspace: "1",
+		Followers: []string{"5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.ID != "2" || tag.Workspace.ID != "1" {
+		t.Errorf("Unexpected tag %+v", tag)
+	}
+}
+
+func TestTag_Delete(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Delete("/tags/1").
+		Reply(200).
+		JSON(o{"data": o{}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	if err := (&Tag{ID: "1"}).Delete(client); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected the tag to be deleted")
+	}
+}
+
+func TestTag_AllTasks(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/tags/1/tasks").
+		MatchParam("limit", "100").
+		Reply(200).
+		JSON(o{
+			"data":      []o{{"gid": "10"}, {"gid": "11"}},
+			"next_page": o{"offset": "abc"},
+		})
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/tags/1/tasks").
+		MatchParam("offset", "abc").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "12"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	tasks, err := (&Tag{ID: "1"}).AllTasks(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 || tasks[2].ID != "12" {
+		t.Errorf("Expected three tasks but saw %v", tasks)
+	}
+}
+
+func TestWorkspace_FindTags(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/workspaces/1/typeahead").
+		MatchParam("resource_type", "tag").
+		MatchParam("query", "urg").
+		MatchParam("count", "5").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "2", "resource_type": "tag", "name": "Urgent"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	tags, err := (&Workspace{ID: "1"}).FindTags(client, "urg", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "Urgent" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+}
@@ -283,6 +283,13 @@ type Task struct {
 	// Read-only. Array of resources referencing tasks that depend on this task.
 	// The objects contain only the ID of the dependent.
 	Dependents []*Task `json:"dependents,omitempty"`
+
+	// Read-only. A URL that points directly to the task within Asana.
+	PermalinkURL string `json:"permalink_url,omitempty"`
+
+	// Read-only. Opt In. The total time recorded in time tracking entries on
+	// this task, in minutes. Only present when time tracking is enabled.
+	ActualTimeMinutes *float64 `json:"actual_time_minutes,omitempty"`
 }
 
 // Fetch loads the full details for this Task
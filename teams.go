@@ -21,17 +21,203 @@ type Team struct {
 	HTMLDescription string `json:"html_description,omitempty"`
 
 	Organization *Workspace `json:"organization,omitempty"`
+
+	// The visibility of the team to users in the same organization.
+	Visibility TeamVisibility `json:"visibility,omitempty"`
 }
 
-// Fetch loads the full details for this Team
-func (t *Team) Fetch(client *Client) error {
+// TeamVisibility controls who in an organization can see and join a team
+type TeamVisibility string
+
+const (
+	TeamVisibilitySecret        TeamVisibility = "secret"
+	TeamVisibilityRequestToJoin TeamVisibility = "request_to_join"
+	TeamVisibilityPublic        TeamVisibility = "public"
+)
+
+// Fetch loads the full details for this Team. When no options are given all
+// fields are requested, as the organization is not returned by default.
+func (t *Team) Fetch(client *Client, options ...*Options) error {
 	client.trace("Loading team details for %q\n", t.Name)
 
-	// Use fields options to request Organization field which is not returned by default
-	_, err := client.get(fmt.Sprintf("/teams/%s", t.ID), nil, t, Fields(*t))
+	if len(options) == 0 {
+		options = []*Options{Fields(*t)}
+	}
+
+	_, err := client.get(fmt.Sprintf("/teams/%s", t.ID), nil, t, options...)
 	return err
 }
 
+// CreateTeamRequest represents a request to create a new team
+type CreateTeamRequest struct {
+	// Required: The name of the team.
+	Name string `json:"name"`
+
+	Description     string         `json:"description,omitempty"`
+	HTMLDescription string         `json:"html_description,omitempty"`
+	Visibility      TeamVisibility `json:"visibility,omitempty"`
+
+	// Set by Workspace.CreateTeam
+	Organization string `json:"organization"`
+}
+
+// CreateTeam adds a new team to this organization
+func (w *Workspace) CreateTeam(client *Client, request *CreateTeamRequest, options ...*Options) (*Team, error) {
+	client.info("Creating team %q in %q\n", request.Name, w.Name)
+
+	request.Organization = w.ID
+	result := &Team{}
+
+	err := client.post("/teams", request, result, options...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateTeamRequest represents a request to update a team. Only the fields
+// which are set are changed.
+type UpdateTeamRequest struct {
+	Name            string         `json:"name,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	HTMLDescription string         `json:"html_description,omitempty"`
+	Visibility      TeamVisibility `json:"visibility,omitempty"`
+}
+
+// Update applies new values to a Team record
+func (t *Team) Update(client *Client, request *UpdateTeamRequest, options ...*Options) error {
+	client.trace("Updating team %q", t.Name)
+
+	return client.put(fmt.Sprintf("/teams/%s", t.ID), request, t, options...)
+}
+
+// TeamMembership describes a user's membership of a team
+type TeamMembership struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	User *User `json:"user,omitempty"`
+	Team *Team `json:"team,omitempty"`
+
+	// Whether the user is a guest on the team.
+	IsGuest bool `json:"is_guest,omitempty"`
+
+	// Whether the user has limited access to the team.
+	IsLimitedAccess bool `json:"is_limited_access,omitempty"`
+
+	// Whether the user is an administrator of the team.
+	IsAdmin bool `json:"is_admin,omitempty"`
+}
+
+type teamUserRequest struct {
+	User string `json:"user"`
+}
+
+// AddUser adds a user to this team. The user may be given as an ID, an email
+// address or "me".
+func (t *Team) AddUser(client *Client, user string) (*TeamMembership, error) {
+	client.info("Adding user %q to team %q\n", user, t.Name)
+
+	result := &TeamMembership{}
+
+	err := client.post(fmt.Sprintf("/teams/%s/addUser", t.ID), &teamUserRequest{User: user}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveUser removes a user from this team. The user may be given as an ID,
+// an email address or "me".
+func (t *Team) RemoveUser(client *Client, user string) error {
+	client.info("Removing user %q from team %q\n", user, t.Name)
+
+	return client.post(fmt.Sprintf("/teams/%s/removeUser", t.ID), &teamUserRequest{User: user}, nil)
+}
+
+// Users returns the compact records for all users who are members of this
+// team
+func (t *Team) Users(client *Client, options ...*Options) ([]*User, *NextPage, error) {
+	client.trace("Listing users in team %q...\n", t.Name)
+	var result []*User
+
+	// Make the request
+	nextPage, err := client.get(fmt.Sprintf("/teams/%s/users", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// AllUsers repeatedly pages through all users who are members of this team
+func (t *Team) AllUsers(client *Client, options ...*Options) ([]*User, error) {
+	var allUsers []*User
+	nextPage := &NextPage{}
+
+	var users []*User
+	var err error
+
+	for nextPage != nil {
+		page := &Options{
+			Limit:  100,
+			Offset: nextPage.Offset,
+		}
+
+		allOptions := append([]*Options{page}, options...)
+		users, nextPage, err = t.Users(client, allOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		allUsers = append(allUsers, users...)
+	}
+	return allUsers, nil
+}
+
+// Memberships returns the memberships of this team, including whether each
+// member is an admin, a guest or has limited access
+func (t *Team) Memberships(client *Client, options ...*Options) ([]*TeamMembership, *NextPage, error) {
+	client.trace("Listing memberships of team %q...\n", t.Name)
+	var result []*TeamMembership
+
+	// Make the request
+	nextPage, err := client.get(fmt.Sprintf("/teams/%s/team_memberships", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+type userTeamsRequestParams struct {
+	Organization string `url:"organization"`
+}
+
+type userTeamMembershipsRequestParams struct {
+	Workspace string `url:"workspace"`
+}
+
+// Teams returns the teams in the given organization which this user is a
+// member of
+func (u *User) Teams(client *Client, workspace string, options ...*Options) ([]*Team, *NextPage, error) {
+	client.trace("Listing teams of user %q...\n", u.ID)
+	var result []*Team
+
+	// Make the request
+	query := userTeamsRequestParams{
+		Organization: workspace,
+	}
+	nextPage, err := client.get(fmt.Sprintf("/users/%s/teams", u.ID), query, &result, options...)
+	return result, nextPage, err
+}
+
+// TeamMemberships returns the memberships this user holds of teams in the
+// given workspace
+func (u *User) TeamMemberships(client *Client, workspace string, options ...*Options) ([]*TeamMembership, *NextPage, error) {
+	client.trace("Listing team memberships of user %q...\n", u.ID)
+	var result []*TeamMembership
+
+	// Make the request
+	query := userTeamMembershipsRequestParams{
+		Workspace: workspace,
+	}
+	nextPage, err := client.get(fmt.Sprintf("/users/%s/team_memberships", u.ID), query, &result, options...)
+	return result, nextPage, err
+}
+
 // Teams returns the compact records for all teams in the organization visible to the authorized user
 func (w *Workspace) Teams(client *Client, options ...*Options) ([]*Team, *NextPage, error) {
 	client.trace("Listing teams in workspace %s...\n", w.ID)
@@ -0,0 +1,170 @@
+package asana
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+)
+
+func TestWorkspace_CreateTeam(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/teams").
+		BodyString(`"data":\{"name":"Platform","description":"Infra","visibility":"secret","organization":"1"\}`).
+		Reply(201).
+		JSON(o{"data": o{"gid": "10", "name": "Platform", "visibility": "secret"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	team, err := (&Workspace{ID: "1"}).CreateTeam(client, &CreateTeamRequest{
+		Name:        "Platform",
+		Description: "Infra",
+		Visibility:  TeamVisibilitySecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if team.ID != "10" || team.Visibility != TeamVisibilitySecret {
+		t.Errorf("Unexpected team %+v", team)
+	}
+}
+
+func TestTeam_Update(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Put("/teams/10").
+		BodyString(`"data":\{"name":"Platform team"\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "10", "name": "Platform team"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	team := &Team{ID: "10", Name: "Platform"}
+	if err := team.Update(client, &UpdateTeamRequest{Name: "Platform team"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if team.Name != "Platform team" {
+		t.Errorf("Expected the team to be updated but saw %q", team.Name)
+	}
+}
+
+func TestTeam_AddRemoveUser(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/teams/10/addUser").
+		BodyString(`"data":\{"user":"someone@example.com"\}`).
+		Reply(200).
+		JSON(o{"data": o{
+			"gid":      "20",
+			"user":     o{"gid": "5", "name": "Someone"},
+			"team":     o{"gid": "10"},
+			"is_guest": true,
+		}})
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/teams/10/removeUser").
+		BodyString(`"data":\{"user":"5"\}`).
+		Reply(200).
+		JSON(o{"data": o{}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	team := &Team{ID: "10"}
+
+	membership, err := team.AddUser(client, "someone@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if membership.ID != "20" || membership.User.ID != "5" || !membership.IsGuest {
+		t.Errorf("Unexpected membership %+v", membership)
+	}
+
+	if err := team.RemoveUser(client, "5"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected both requests to be made")
+	}
+}
+
+func TestTeam_Users(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/teams/10/users").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "5", "name": "Someone"}, {"gid": "6", "name": "Else"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	users, err := (&Team{ID: "10"}).AllUsers(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 2 || users[1].ID != "6" {
+		t.Errorf("Unexpected users %+v", users)
+	}
+}
+
+func TestTeam_Memberships(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/teams/10/team_memberships").
+		Reply(200).
+		JSON(o{"data": []o{
+			{"gid": "20", "user": o{"gid": "5"}, "is_admin": true},
+			{"gid": "21", "user": o{"gid": "6"}, "is_limited_access": true},
+		}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	memberships, _, err := (&Team{ID: "10"}).Memberships(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(memberships) != 2 || !memberships[0].IsAdmin || !memberships[1].IsLimitedAccess {
+		t.Errorf("Unexpected memberships %+v", memberships)
+	}
+}
+
+func TestUser_Teams(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/users/me/teams").
+		MatchParam("organization", "1").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "10", "name": "Platform"}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	teams, _, err := (&User{ID: "me"}).Teams(client, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(teams) != 1 || teams[0].Name != "Platform" {
+		t.Errorf("Unexpected teams %+v", teams)
+	}
+}
+
+func TestUser_TeamMemberships(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/users/me/team_memberships").
+		MatchParam("workspace", "1").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "20", "team": o{"gid": "10"}, "is_admin": true}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	memberships, _, err := (&User{ID: "me"}).TeamMemberships(client, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 1 || !memberships[0].IsAdmin {
+		t.Errorf("Unexpected memberships %+v", memberships)
+	}
+}
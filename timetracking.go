@@ -0,0 +1,217 @@
+package asana
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeTrackingEntry records time spent on a task. Time tracking entries are
+// only available in workspaces with time tracking enabled; the totals also
+// appear in the actual time custom field of the task.
+type TimeTrackingEntry struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Time in minutes tracked by the entry
+	DurationMinutes int `json:"duration_minutes,omitempty"`
+
+	// The day that this entry is logged on
+	EnteredOn *Date `json:"entered_on,omitempty"`
+
+	// The project which the time is attributed to, if any
+	AttributableTo *Project `json:"attributable_to,omitempty"`
+
+	// Read-only. The user who created the entry, and whose time it records.
+	CreatedBy *User `json:"created_by,omitempty"`
+
+	// Read-only. The task the time was spent on.
+	Task *Task `json:"task,omitempty"`
+
+	// Read-only. The time at which this object was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// TimeTrackingEntryRequest holds the fields of a time tracking entry which
+// can be set when creating or updating it
+type TimeTrackingEntryRequest struct {
+	// Time in minutes tracked by the entry. Required when creating an entry.
+	DurationMinutes int `json:"duration_minutes,omitempty"`
+
+	// The day that this entry is logged on. Defaults to today.
+	EnteredOn *Date `json:"entered_on,omitempty"`
+
+	// Optional. The ID of the project which the time is attributed to.
+	AttributableTo string `json:"attributable_to,omitempty"`
+}
+
+// Validate checks the request before it is sent
+func (r *TimeTrackingEntryRequest) Validate() error {
+	if r.DurationMinutes < 0 {
+		return errors.New("Time tracking entry duration must not be negative")
+	}
+	return nil
+}
+
+// createTimeTrackingEntryRequest is a TimeTrackingEntryRequest for a new
+// entry, which must have a duration
+type createTimeTrackingEntryRequest struct {
+	*TimeTrackingEntryRequest
+}
+
+// Validate checks the request before it is sent
+func (r createTimeTrackingEntryRequest) Validate() error {
+	if r.DurationMinutes <= 0 {
+		return errors.New("Time tracking entry duration must be positive")
+	}
+	return nil
+}
+
+// TimeTrackingEntries lists the time tracking entries of this task
+func (t *Task) TimeTrackingEntries(client *Client, options ...*Options) ([]*TimeTrackingEntry, *NextPage, error) {
+	client.trace("Listing time tracking entries for %q", t.Name)
+
+	var result []*TimeTrackingEntry
+
+	// Make the request
+	nextPage, err := client.get(fmt.Sprintf("/tasks/%s/time_tracking_entries", t.ID), nil, &result, options...)
+	return result, nextPage, err
+}
+
+// AllTimeTrackingEntries repeatedly pages through all time tracking entries
+// of this task
+func (t *Task) AllTimeTrackingEntries(client *Client, options ...*Options) ([]*TimeTrackingEntry, error) {
+	var allEntries []*TimeTrackingEntry
+	nextPage := &NextPage{}
+
+	var entries []*TimeTrackingEntry
+	var err error
+
+	for nextPage != nil {
+		page := &Options{
+			Limit:  100,
+			Offset: nextPage.Offset,
+		}
+
+		allOptions := append([]*Options{page}, options...)
+		entries, nextPage, err = t.TimeTrackingEntries(client, allOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		allEntries = append(allEntries, entries...)
+	}
+	return allEntries, nil
+}
+
+// CreateTimeTrackingEntry records time spent by the authorized user on this
+// task
+func (t *Task) CreateTimeTrackingEntry(client *Client, entry *TimeTrackingEntryRequest, options ...*Options) (*TimeTrackingEntry, error) {
+	client.info("Creating time tracking entry for task %q", t.Name)
+
+	result := &TimeTrackingEntry{}
+
+	err := client.post(fmt.Sprintf("/tasks/%s/time_tracking_entries", t.ID), createTimeTrackingEntryRequest{entry}, result, options...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Fetch loads the full details for this TimeTrackingEntry
+func (e *TimeTrackingEntry) Fetch(client *Client, options ...*Options) error {
+	client.trace("Loading details for time tracking entry %s", e.ID)
+
+	_, err := client.get(fmt.Sprintf("/time_tracking_entries/%s", e.ID), nil, e, options...)
+	return err
+}
+
+// Update changes the duration, date or project of this entry. Fields left
+// empty in the request are not changed.
+func (e *TimeTrackingEntry) Update(client *Client, update *TimeTrackingEntryRequest, options ...*Options) error {
+	client.info("Updating time tracking entry %s", e.ID)
+
+	return client.put(fmt.Sprintf("/time_tracking_entries/%s", e.ID), update, e, options...)
+}
+
+// Delete removes this entry
+func (e *TimeTrackingEntry) Delete(client *Client) error {
+	client.info("Deleting time tracking entry %s", e.ID)
+
+	return client.delete(fmt.Sprintf("/time_tracking_entries/%s", e.ID))
+}
+
+// TimesheetRow is the total time recorded by one user against one project
+// in a week
+type TimesheetRow struct {
+	// The first day of the week
+	Week time.Time
+
+	// The user who recorded the time
+	User *User
+
+	// The project the time is attributed to, or nil for unattributed time
+	Project *Project
+
+	Minutes int
+}
+
+// WeeklyTimesheet totals time tracking entries by week, user and project.
+// Weeks begin on weekStart. Rows are sorted by week, then user ID, then
+// project ID. Entries without a date are dated by their creation time, and
+// skipped if that is not known either.
+func WeeklyTimesheet(entries []*TimeTrackingEntry, weekStart time.Weekday) []*TimesheetRow {
+	type key struct {
+		week    time.Time
+		user    string
+		project string
+	}
+
+	rows := map[key]*TimesheetRow{}
+	for _, entry := range entries {
+		var day time.Time
+		switch {
+		case entry.EnteredOn != nil:
+			day = time.Time(*entry.EnteredOn)
+		case entry.CreatedAt != nil:
+			day = *entry.CreatedAt
+		default:
+			continue
+		}
+
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		week := day.AddDate(0, 0, -((int(day.Weekday()) - int(weekStart) + 7) % 7))
+
+		k := key{week: week}
+		if entry.CreatedBy != nil {
+			k.user = entry.CreatedBy.ID
+		}
+		if entry.AttributableTo != nil {
+			k.project = entry.AttributableTo.ID
+		}
+
+		row, ok := rows[k]
+		if !ok {
+			row = &TimesheetRow{Week: week, User: entry.CreatedBy, Project: entry.AttributableTo}
+			rows[k] = row
+		}
+		row.Minutes += entry.DurationMinutes
+	}
+
+	keys := make([]key, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(a.week.Compare(b.week), cmp.Compare(a.user, b.user), cmp.Compare(a.project, b.project))
+	})
+
+	result := make([]*TimesheetRow, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, rows[k])
+	}
+	return result
+}
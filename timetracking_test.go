@@ -0,0 +1,155 @@
+package asana
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+)
+
+func TestWeeklyTimesheet(t *testing.T) {
+	date := func(s string) *Date {
+		d, _ := time.Parse(time.DateOnly, s)
+		return (*Date)(&d)
+	}
+	alice := &User{ID: "1"}
+	bob := &User{ID: "2"}
+	billing := &Project{ID: "10"}
+
+	entries := []*TimeTrackingEntry{
+		// Monday and Sunday of the same week
+		{DurationMinutes: 30, EnteredOn: date("2024-03-04"), CreatedBy: alice, AttributableTo: billing},
+		{DurationMinutes: 45, EnteredOn: date("2024-03-10"), CreatedBy: alice, AttributableTo: billing},
+		{DurationMinutes: 15, EnteredOn: date("2024-03-05"), CreatedBy: alice},
+		{DurationMinutes: 60, EnteredOn: date("2024-03-06"), CreatedBy: bob, AttributableTo: billing},
+		// The following Monday
+		{DurationMinutes: 20, EnteredOn: date("2024-03-11"), CreatedBy: alice, AttributableTo: billing},
+		{DurationMinutes: 5},
+	}
+
+	rows := WeeklyTimesheet(entries, time.Monday)
+
+	expected := []struct {
+		week    string
+		user    string
+		project string
+		minutes int
+	}{
+		{"2024-03-04", "1", "", 15},
+		{"2024-03-04", "1", "10", 75},
+		{"2024-03-04", "2", "10", 60},
+		{"2024-03-11", "1", "10", 20},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows but saw %d", len(expected), len(rows))
+	}
+	for i, e := range expected {
+		row := rows[i]
+		project := ""
+		if row.Project != nil {
+			project = row.Project.ID
+		}
+		if row.Week.Format(time.DateOnly) != e.week || row.User.ID != e.user || project != e.project || row.Minutes != e.minutes {
+			t.Errorf("Row %d: expected %v but saw %s %s %s %d", i, e, row.Week.Format(time.DateOnly), row.User.ID, project, row.Minutes)
+		}
+	}
+}
+
+func TestTask_CreateTimeTrackingEntry(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/tasks/1/time_tracking_entries").
+		BodyString(`"data":\{"duration_minutes":90,"entered_on":"2024-03-04","attributable_to":"10"\}`).
+		Reply(201).
+		JSON(o{"data": o{"gid": "20", "duration_minutes": 90, "entered_on": "2024-03-04"}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	day, _ := time.Parse(time.DateOnly, "2024-03-04")
+	entry, err := (&Task{ID: "1"}).CreateTimeTrackingEntry(client, &TimeTrackingEntryRequest{
+		DurationMinutes: 90,
+		EnteredOn:       (*Date)(&day),
+		AttributableTo:  "10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.ID != "20" || entry.DurationMinutes != 90 || time.Time(*entry.EnteredOn) != day {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+
+	// A new entry must have a duration
+	for _, minutes := range []int{0, -5} {
+		if _, err := (&Task{ID: "1"}).CreateTimeTrackingEntry(client, &TimeTrackingEntryRequest{DurationMinutes: minutes}); err == nil {
+			t.Errorf("Expected a duration of %d minutes to be rejected", minutes)
+		}
+	}
+}
+
+func TestTimeTrackingEntry_Update(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Put("/time_tracking_entries/20").
+		BodyString(`"data":\{"duration_minutes":45\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "20", "duration_minutes": 45}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	entry := &TimeTrackingEntry{ID: "20", DurationMinutes: 90}
+	if err := entry.Update(client, &TimeTrackingEntryRequest{DurationMinutes: 45}); err != nil {
+		t.Fatal(err)
+	}
+	if entry.DurationMinutes != 45 {
+		t.Errorf("Expected 45 minutes but saw %d", entry.DurationMinutes)
+	}
+
+	if err := entry.Update(client, &TimeTrackingEntryRequest{DurationMinutes: -1}); err == nil {
+		t.Error("Expected a negative duration to be rejected")
+	}
+}
+
+func TestTimeTrackingEntry_Delete(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Delete("/time_tracking_entries/20").
+		Reply(200).
+		JSON(o{"data": o{}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	if err := (&TimeTrackingEntry{ID: "20"}).Delete(client); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected the entry to be deleted")
+	}
+}
+
+func TestTask_AllTimeTrackingEntries(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/tasks/1/time_tracking_entries").
+		MatchParam("limit", "100").
+		Reply(200).
+		JSON(o{
+			"data":      []o{{"gid": "20", "duration_minutes": 30, "created_by": o{"gid": "5"}}},
+			"next_page": o{"offset": "abc"},
+		})
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/tasks/1/time_tracking_entries").
+		MatchParam("offset", "abc").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "21", "duration_minutes": 15}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	entries, err := (&Task{ID: "1"}).AllTimeTrackingEntries(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].CreatedBy.ID != "5" || entries[1].DurationMinutes != 15 {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,169 @@
+package asana
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore which holds no token for the
+// requested key
+var ErrTokenNotFound = errors.New("No token stored")
+
+// TokenStore persists OAuth tokens, keyed for example by user ID, so that
+// authorization survives restarts. Implementations must be safe for
+// concurrent use.
+type TokenStore interface {
+	// Load returns the token stored under key, or ErrTokenNotFound
+	Load(key string) (*oauth2.Token, error)
+
+	// Save stores the token under key, replacing any previous token
+	Save(key string, token *oauth2.Token) error
+}
+
+// FileTokenStore is a TokenStore which keeps each token in its own file in a
+// directory. Files are readable only by the current user and are replaced
+// atomically, so a crash never leaves a partly written token.
+type FileTokenStore struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+// NewFileTokenStore creates a store in dir, creating the directory if needed
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "Unable to create token store")
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// path returns the file for key. Keys are hashed so that any string is a
+// safe file name.
+func (s *FileTokenStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load implements TokenStore
+func (s *FileTokenStore) Load(key string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read token")
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode token")
+	}
+	return token, nil
+}
+
+// Save implements TokenStore
+func (s *FileTokenStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode token")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.CreateTemp(s.dir, ".token-*")
+	if err != nil {
+		return errors.Wrap(err, "Unable to save token")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Unable to save token")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "Unable to save token")
+	}
+	if err := os.Rename(f.Name(), s.path(key)); err != nil {
+		return errors.Wrap(err, "Unable to save token")
+	}
+	return nil
+}
+
+// savingTokenSource saves each new token returned by its source
+type savingTokenSource struct {
+	store TokenStore
+	key   string
+	src   oauth2.TokenSource
+
+	mu    sync.Mutex
+	saved string
+}
+
+// ReuseTokenSource returns a TokenSource which, like oauth2.ReuseTokenSource,
+// returns token until it expires and then gets a new one from src. Every new
+// token is saved to the store under key. If saving fails the error is
+// returned and saving is retried on the next call.
+func ReuseTokenSource(store TokenStore, key string, token *oauth2.Token, src oauth2.TokenSource) oauth2.TokenSource {
+	s := &savingTokenSource{
+		store: store,
+		key:   key,
+		src:   oauth2.ReuseTokenSource(token, src),
+	}
+	if token != nil {
+		s.saved = token.AccessToken
+	}
+	return s
+}
+
+// Token implements oauth2.TokenSource
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token.AccessToken != s.saved {
+		if err := s.store.Save(s.key, token); err != nil {
+			return nil, err
+		}
+		s.saved = token.AccessToken
+	}
+	return token, nil
+}
+
+// TokenSource returns a source of tokens for the user whose token is stored
+// under key, refreshing it when it expires and saving every refreshed token
+// back to the store
+func (a *App) TokenSource(ctx context.Context, store TokenStore, key string) (oauth2.TokenSource, error) {
+	token, err := store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	return ReuseTokenSource(store, key, token, a.config.TokenSource(ctx, token)), nil
+}
+
+// NewStoredClient creates a new Asana client for the user whose token is
+// stored under key. Refreshed tokens are saved back to the store, so the
+// client can be recreated after a restart without authorizing again.
+func (a *App) NewStoredClient(ctx context.Context, store TokenStore, key string, opts ...ClientOption) (*Client, error) {
+	ts, err := a.TokenSource(ctx, store, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientWithTokenSource(ts, opts...)
+}
@@ -0,0 +1,85 @@
+package asana
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load("missing"); err != ErrTokenNotFound {
+		t.Errorf("Expected ErrTokenNotFound but saw %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("user/%d", i%4)
+			if err := store.Save(key, &oauth2.Token{AccessToken: key, RefreshToken: "r"}); err != nil {
+				t.Error(err)
+			}
+			if _, err := store.Load(key); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	token, err := store.Load("user/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "user/2" || token.RefreshToken != "r" {
+		t.Errorf("Unexpected token %+v", token)
+	}
+}
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+func TestReuseTokenSource_SavesRefreshedTokens(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshes := 0
+	src := tokenSourceFunc(func() (*oauth2.Token, error) {
+		refreshes++
+		return &oauth2.Token{AccessToken: fmt.Sprintf("new%d", refreshes), RefreshToken: "r", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "r", Expiry: time.Now().Add(-time.Hour)}
+	ts := ReuseTokenSource(store, "user", expired, src)
+
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "new1" {
+			t.Errorf("Expected the refreshed token to be reused but saw %q", token.AccessToken)
+		}
+	}
+
+	saved, err := store.Load("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "new1" || refreshes != 1 {
+		t.Errorf("Expected one saved refresh but saw %q after %d refreshes", saved.AccessToken, refreshes)
+	}
+}
@@ -0,0 +1,158 @@
+package asana
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TypeaheadType is a type of object which can be found by typeahead search
+type TypeaheadType string
+
+// Types of object supported by typeahead search
+const (
+	TypeaheadUser        TypeaheadType = "user"
+	TypeaheadProject     TypeaheadType = "project"
+	TypeaheadPortfolio   TypeaheadType = "portfolio"
+	TypeaheadTag         TypeaheadType = "tag"
+	TypeaheadTask        TypeaheadType = "task"
+	TypeaheadCustomField TypeaheadType = "custom_field"
+	TypeaheadGoal        TypeaheadType = "goal"
+	TypeaheadTeam        TypeaheadType = "team"
+)
+
+// TypeaheadResult is an object found by typeahead search. Use the method
+// matching the ResourceType to convert it to a typed object.
+type TypeaheadResult struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The base type of this resource
+	ResourceType TypeaheadType `json:"resource_type,omitempty"`
+
+	// Read-only. The name of the object.
+	Name string `json:"name,omitempty"`
+}
+
+// User returns the result as a User, or nil if it is not a user
+func (r *TypeaheadResult) User() *User {
+	if r == nil || r.ResourceType != TypeaheadUser {
+		return nil
+	}
+	return &User{ID: r.ID, Name: r.Name}
+}
+
+// Project returns the result as a Project, or nil if it is not a project
+func (r *TypeaheadResult) Project() *Project {
+	if r == nil || r.ResourceType != TypeaheadProject {
+		return nil
+	}
+	return &Project{ID: r.ID, ProjectBase: ProjectBase{Name: r.Name}}
+}
+
+// Portfolio returns the result as a Portfolio, or nil if it is not a
+// portfolio
+func (r *TypeaheadResult) Portfolio() *Portfolio {
+	if r == nil || r.ResourceType != TypeaheadPortfolio {
+		return nil
+	}
+	return &Portfolio{ID: r.ID, Name: r.Name}
+}
+
+// Tag returns the result as a Tag, or nil if it is not a tag
+func (r *TypeaheadResult) Tag() *Tag {
+	if r == nil || r.ResourceType != TypeaheadTag {
+		return nil
+	}
+	return &Tag{ID: r.ID, TagBase: TagBase{Name: r.Name}}
+}
+
+// Task returns the result as a Task, or nil if it is not a task
+func (r *TypeaheadResult) Task() *Task {
+	if r == nil || r.ResourceType != TypeaheadTask {
+		return nil
+	}
+	return &Task{ID: r.ID, TaskBase: TaskBase{Name: r.Name}}
+}
+
+// CustomField returns the result as a CustomField, or nil if it is not a
+// custom field
+func (r *TypeaheadResult) CustomField() *CustomField {
+	if r == nil || r.ResourceType != TypeaheadCustomField {
+		return nil
+	}
+	return &CustomField{ID: r.ID, CustomFieldBase: CustomFieldBase{Name: r.Name}}
+}
+
+// Goal returns the result as a Goal, or nil if it is not a goal
+func (r *TypeaheadResult) Goal() *Goal {
+	if r == nil || r.ResourceType != TypeaheadGoal {
+		return nil
+	}
+	return &Goal{ID: r.ID, Name: r.Name}
+}
+
+// Team returns the result as a Team, or nil if it is not a team
+func (r *TypeaheadResult) Team() *Team {
+	if r == nil || r.ResourceType != TypeaheadTeam {
+		return nil
+	}
+	return &Team{ID: r.ID, Name: r.Name}
+}
+
+type typeaheadRequestParams struct {
+	ResourceType TypeaheadType `url:"resource_type"`
+	Query        string        `url:"query"`
+	Count        int           `url:"count,omitempty"`
+}
+
+// Typeahead looks up objects of the given type in this workspace whose names
+// match the query, as the Asana search box does. Results are ordered by
+// relevance and at most count results are returned; a count of zero uses
+// the API default of 20. Users may also be matched by email address.
+func (w *Workspace) Typeahead(client *Client, resourceType TypeaheadType, query string, count int, options ...*Options) ([]*TypeaheadResult, error) {
+	client.trace("Searching for %s matching %q in %q", resourceType, query, w.Name)
+
+	var result []*TypeaheadResult
+
+	params := typeaheadRequestParams{
+		ResourceType: resourceType,
+		Query:        query,
+		Count:        count,
+	}
+	_, err := client.get(fmt.Sprintf("/workspaces/%s/typeahead", w.ID), params, &result, options...)
+	return result, err
+}
+
+// ExactMatch returns the only result whose name equals the query, ignoring
+// case. Typeahead results are only suggestions, so a partial match is never
+// chosen: if there is no exact match the error lists the results found
+// instead, and if several results share the name the error lists their IDs.
+func ExactMatch(results []*TypeaheadResult, query string) (*TypeaheadResult, error) {
+	var matches []*TypeaheadResult
+	for _, r := range results {
+		if strings.EqualFold(r.Name, query) {
+			matches = append(matches, r)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return nil, errors.Errorf("%d results are named %q: %s", len(matches), query, describeResults(matches))
+	case len(results) > 0:
+		return nil, errors.Errorf("Nothing is named %q, found %s", query, describeResults(results))
+	default:
+		return nil, errors.Errorf("Nothing matches %q", query)
+	}
+}
+
+func describeResults(results []*TypeaheadResult) string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = fmt.Sprintf("%q (%s %s)", r.Name, r.ResourceType, r.ID)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,97 @@
+package asana
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/h2non/gock"
+)
+
+func TestWorkspace_Typeahead(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/workspaces/1/typeahead").
+		MatchParams(map[string]string{"resource_type": "goal", "query": "Grow", "count": "5"}).
+		Reply(200).
+		JSON(o{"data": []o{
+			{"gid": "10", "resource_type": "goal", "name": "Grow revenue"},
+			{"gid": "11", "resource_type": "goal", "name": "grow"},
+		}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	results, err := (&Workspace{ID: "1"}).Typeahead(client, TypeaheadGoal, "Grow", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results but saw %d", len(results))
+	}
+
+	goal := results[0].Goal()
+	if goal == nil || goal.ID != "10" || goal.Name != "Grow revenue" {
+		t.Errorf("Unexpected goal %+v", goal)
+	}
+	if results[0].Project() != nil || results[0].Task() != nil {
+		t.Error("Expected a goal not to convert to other types")
+	}
+
+	match, err := ExactMatch(results, "Grow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match.ID != "11" {
+		t.Errorf("Expected the exact match 11 but saw %s", match.ID)
+	}
+}
+
+func TestExactMatch(t *testing.T) {
+	results := []*TypeaheadResult{
+		{ID: "1", ResourceType: TypeaheadTask, Name: "Write report"},
+		{ID: "2", ResourceType: TypeaheadTask, Name: "Write report draft"},
+	}
+
+	if _, err := ExactMatch(results, "Write"); err == nil {
+		t.Error("Expected no partial match")
+	} else if !strings.Contains(err.Error(), `"Write report draft" (task 2)`) {
+		t.Errorf("Expected the candidates to be listed but saw %v", err)
+	}
+
+	if _, err := ExactMatch(nil, "Write"); err == nil {
+		t.Error("Expected an error for no results")
+	}
+
+	results = append(results, &TypeaheadResult{ID: "3", ResourceType: TypeaheadTask, Name: "write REPORT"})
+	if _, err := ExactMatch(results, "Write report"); err == nil {
+		t.Error("Expected an error for an ambiguous name")
+	} else if !strings.Contains(err.Error(), "2 results") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestTypeaheadResult_Converters(t *testing.T) {
+	for _, r := range []*TypeaheadResult{
+		{ID: "1", ResourceType: TypeaheadUser},
+		{ID: "1", ResourceType: TypeaheadProject},
+		{ID: "1", ResourceType: TypeaheadPortfolio},
+		{ID: "1", ResourceType: TypeaheadTag},
+		{ID: "1", ResourceType: TypeaheadTask},
+		{ID: "1", ResourceType: TypeaheadCustomField},
+		{ID: "1", ResourceType: TypeaheadGoal},
+		{ID: "1", ResourceType: TypeaheadTeam},
+	} {
+		converted := 0
+		for _, ok := range []bool{
+			r.User() != nil, r.Project() != nil, r.Portfolio() != nil, r.Tag() != nil,
+			r.Task() != nil, r.CustomField() != nil, r.Goal() != nil, r.Team() != nil,
+		} {
+			if ok {
+				converted++
+			}
+		}
+		if converted != 1 {
+			t.Errorf("Expected a %s to convert to exactly one type but saw %d", r.ResourceType, converted)
+		}
+	}
+}
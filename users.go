@@ -1,6 +1,9 @@
 package asana
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 // User represents an account in Asana that can be given access to various
 // workspaces, projects, and tasks.
@@ -40,14 +43,25 @@ func (c *Client) CurrentUser() (*User, error) {
 	return result, err
 }
 
-// Fetch loads the full details for this User
+// Fetch loads the full details for this User. If the ID is not known the
+// user is looked up by Email instead, so an email address can be resolved to
+// a user ID.
 func (u *User) Fetch(client *Client, options ...*Options) error {
-	client.trace("Loading details for user %q", u.ID)
+	client.trace("Loading details for user %q", u.userKey())
 
-	_, err := client.get(fmt.Sprintf("/users/%s", u.ID), nil, u, options...)
+	_, err := client.get(fmt.Sprintf("/users/%s", u.userKey()), nil, u, options...)
 	return err
 }
 
+// userKey identifies the user in request paths: by ID, or by email address
+// when the ID is not known
+func (u *User) userKey() string {
+	if u.ID == "" && u.Email != "" {
+		return url.PathEscape(u.Email)
+	}
+	return u.ID
+}
+
 // Users returns the compact records for all users in the organization visible to the authorized user
 func (w *Workspace) Users(client *Client, options ...*Options) ([]*User, *NextPage, error) {
 	client.trace("Listing users in workspace %s...\n", w.ID)
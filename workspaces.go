@@ -2,6 +2,7 @@ package asana
 
 import (
 	"fmt"
+	"time"
 )
 
 // Workspace is the highest-level organizational unit in Asanc. All projects
@@ -76,3 +77,143 @@ func (c *Client) AllWorkspaces(options ...*Options) ([]*Workspace, error) {
 	}
 	return allWorkspaces, nil
 }
+
+type workspaceUserRequest struct {
+	User string `json:"user"`
+}
+
+// AddUser adds a user to this workspace or organization. The user may be
+// given as an ID, an email address or "me". Returns the compact record of the
+// added user.
+func (w *Workspace) AddUser(client *Client, user string) (*User, error) {
+	client.info("Adding user %q to workspace %q\n", user, w.Name)
+
+	result := &User{}
+
+	err := client.post(fmt.Sprintf("/workspaces/%s/addUser", w.ID), &workspaceUserRequest{User: user}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveUser removes a user from this workspace or organization. The user
+// may be given as an ID, an email address or "me".
+func (w *Workspace) RemoveUser(client *Client, user string) error {
+	client.info("Removing user %q from workspace %q\n", user, w.Name)
+
+	return client.post(fmt.Sprintf("/workspaces/%s/removeUser", w.ID), &workspaceUserRequest{User: user}, nil)
+}
+
+// UserTaskList represents the tasks assigned to a particular user, shown as
+// My Tasks in Asana
+type UserTaskList struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	// Read-only. The name of the object.
+	Name string `json:"name,omitempty"`
+
+	// Read-only. The owner of the user task list.
+	Owner *User `json:"owner,omitempty"`
+
+	// Read-only. The workspace in which the user task list is located.
+	Workspace *Workspace `json:"workspace,omitempty"`
+}
+
+// VacationDates are the dates during which a user is marked as away
+type VacationDates struct {
+	StartOn *Date `json:"start_on,omitempty"`
+	EndOn   *Date `json:"end_on,omitempty"`
+}
+
+// WorkspaceMembership describes a user's membership of a workspace or
+// organization
+type WorkspaceMembership struct {
+	// Read-only. Globally unique ID of the object
+	ID string `json:"gid,omitempty"`
+
+	User      *User      `json:"user,omitempty"`
+	Workspace *Workspace `json:"workspace,omitempty"`
+
+	// Read-only. The user's My Tasks list in the workspace.
+	UserTaskList *UserTaskList `json:"user_task_list,omitempty"`
+
+	// Read-only. Whether the user is active in the workspace. Deactivated
+	// users keep their membership but can no longer sign in.
+	IsActive bool `json:"is_active,omitempty"`
+
+	// Read-only. Whether the user is an administrator of the workspace.
+	IsAdmin bool `json:"is_admin,omitempty"`
+
+	// Read-only. Whether the user is a guest in the workspace.
+	IsGuest bool `json:"is_guest,omitempty"`
+
+	// Read-only. The dates the user is on vacation, if set.
+	VacationDates *VacationDates `json:"vacation_dates,omitempty"`
+
+	// Read-only. The time at which this object was created.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Fetch loads the full details for this WorkspaceMembership
+func (m *WorkspaceMembership) Fetch(client *Client, options ...*Options) error {
+	client.trace("Loading details for workspace membership %s\n", m.ID)
+
+	_, err := client.get(fmt.Sprintf("/workspace_memberships/%s", m.ID), nil, m, options...)
+	return err
+}
+
+type workspaceMembershipsRequestParams struct {
+	User string `url:"user,omitempty"`
+}
+
+// Memberships returns the memberships of this workspace. If user is not
+// empty only the membership of that user is returned.
+func (w *Workspace) Memberships(client *Client, user string, options ...*Options) ([]*WorkspaceMembership, *NextPage, error) {
+	client.trace("Listing memberships of workspace %q...\n", w.Name)
+	var result []*WorkspaceMembership
+
+	// Make the request
+	query := workspaceMembershipsRequestParams{
+		User: user,
+	}
+	nextPage, err := client.get(fmt.Sprintf("/workspaces/%s/workspace_memberships", w.ID), query, &result, options...)
+	return result, nextPage, err
+}
+
+// AllMemberships repeatedly pages through all memberships of this workspace
+func (w *Workspace) AllMemberships(client *Client, options ...*Options) ([]*WorkspaceMembership, error) {
+	var allMemberships []*WorkspaceMembership
+	nextPage := &NextPage{}
+
+	var memberships []*WorkspaceMembership
+	var err error
+
+	for nextPage != nil {
+		page := &Options{
+			Limit:  100,
+			Offset: nextPage.Offset,
+		}
+
+		allOptions := append([]*Options{page}, options...)
+		memberships, nextPage, err = w.Memberships(client, "", allOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		allMemberships = append(allMemberships, memberships...)
+	}
+	return allMemberships, nil
+}
+
+// WorkspaceMemberships returns the memberships this user holds of workspaces
+// and organizations visible to the authorized user
+func (u *User) WorkspaceMemberships(client *Client, options ...*Options) ([]*WorkspaceMembership, *NextPage, error) {
+	client.trace("Listing workspace memberships of user %q...\n", u.ID)
+	var result []*WorkspaceMembership
+
+	// Make the request
+	nextPage, err := client.get(fmt.Sprintf("/users/%s/workspace_memberships", u.userKey()), nil, &result, options...)
+	return result, nextPage, err
+}
@@ -0,0 +1,115 @@
+package asana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/h2non/gock"
+)
+
+func TestUser_FetchByEmail(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.Write([]byte(`{"data": {"gid": "5", "name": "Someone", "email": "some/one+x@example.com"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(WithBaseURL(server.URL))
+	user := &User{Email: "some/one+x@example.com"}
+	if err := user.Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/users/some%2Fone+x@example.com" {
+		t.Errorf("Expected the email address to be escaped in the path but saw %s", path)
+	}
+	if user.ID != "5" {
+		t.Errorf("Expected user 5 but saw %q", user.ID)
+	}
+
+	// Once the ID is known it is used instead
+	if err := user.Fetch(client); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/users/5" {
+		t.Errorf("Expected the user to be fetched by ID but saw %s", path)
+	}
+}
+
+func TestWorkspace_AddRemoveUser(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/workspaces/1/addUser").
+		BodyString(`"data":\{"user":"someone@example.com"\}`).
+		Reply(200).
+		JSON(o{"data": o{"gid": "5", "name": "Someone"}})
+	gock.New("https://app.asana.com/api/1.0").
+		Post("/workspaces/1/removeUser").
+		BodyString(`"data":\{"user":"5"\}`).
+		Reply(200).
+		JSON(o{"data": o{}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+	workspace := &Workspace{ID: "1"}
+
+	user, err := workspace.AddUser(client, "someone@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "5" {
+		t.Errorf("Expected user 5 but saw %q", user.ID)
+	}
+
+	if err := workspace.RemoveUser(client, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Error("Expected both requests to be made")
+	}
+}
+
+func TestWorkspace_Memberships(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/workspaces/1/workspace_memberships").
+		MatchParam("user", "5").
+		Reply(200).
+		JSON(o{"data": []o{{
+			"gid":            "30",
+			"user":           o{"gid": "5"},
+			"workspace":      o{"gid": "1"},
+			"is_admin":       true,
+			"is_active":      true,
+			"vacation_dates": o{"start_on": "2024-07-01", "end_on": "2024-07-14"},
+		}}})
+	gock.New("https://app.asana.com/api/1.0").
+		Get("/users/5/workspace_memberships").
+		Reply(200).
+		JSON(o{"data": []o{{"gid": "30", "workspace": o{"gid": "1"}}, {"gid": "31", "workspace": o{"gid": "2"}}}})
+
+	client, _ := NewClient(WithHTTPClient(http.DefaultClient))
+
+	memberships, _, err := (&Workspace{ID: "1"}).Memberships(client, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 1 {
+		t.Fatalf("Expected one membership but saw %d", len(memberships))
+	}
+	m := memberships[0]
+	if m.ID != "30" || !m.IsAdmin || !m.IsActive || m.VacationDates == nil || m.VacationDates.EndOn == nil {
+		t.Errorf("Unexpected membership %+v", m)
+	}
+
+	memberships, _, err = (&User{ID: "5"}).WorkspaceMemberships(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 2 || memberships[1].Workspace.ID != "2" {
+		t.Errorf("Unexpected memberships %+v", memberships)
+	}
+}